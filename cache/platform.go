@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+var (
+	runnerOSToGo = map[string]string{
+		"Linux":   "linux",
+		"Windows": "windows",
+		"macOS":   "darwin",
+	}
+	runnerArchToGo = map[string]string{
+		"X86":   "386",
+		"X64":   "amd64",
+		"ARM":   "arm",
+		"ARM64": "arm64",
+	}
+)
+
+func platformOS() string {
+	if v, ok := runnerOSToGo[core.RunnerOS()]; ok {
+		return v
+	}
+	return runtime.GOOS
+}
+
+func platformArch() string {
+	if v, ok := runnerArchToGo[core.RunnerArch()]; ok {
+		return v
+	}
+	return runtime.GOARCH
+}
+
+func platformExt(goos string) string {
+	if goos == "windows" {
+		return "zip"
+	}
+	return "tar.gz"
+}
+
+func override(overrides map[string]string, v string) string {
+	if o, ok := overrides[v]; ok {
+		return o
+	}
+	return v
+}
+
+// PlatformURL expands the {os}, {arch} and {ext} placeholders of urlTemplate for the runner platform.
+// os and arch use the go naming, for example linux, darwin, amd64 or arm64, ext is zip on windows and tar.gz otherwise.
+// overrides replaces those values for tools with a non standard naming, for example {"amd64": "x86_64"}
+func PlatformURL(urlTemplate string, overrides map[string]string) string {
+	goos := platformOS()
+	return strings.NewReplacer(
+		"{os}", override(overrides, goos),
+		"{arch}", override(overrides, platformArch()),
+		"{ext}", override(overrides, platformExt(goos)),
+	).Replace(urlTemplate)
+}
+
+// DownloadToolForPlatform downloads the tool asset matching the runner platform, see PlatformURL
+func DownloadToolForPlatform(urlTemplate string, options *DownloadToolOptions) (string, error) {
+	var overrides map[string]string
+	if options != nil {
+		overrides = options.PlatformOverrides
+	}
+	return DownloadTool(PlatformURL(urlTemplate, overrides), options)
+}
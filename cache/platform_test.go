@@ -0,0 +1,53 @@
+package cache_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/actions-go/toolkit/cache"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func setRunner(osName, arch string) func() {
+	previousOS, previousArch := os.Getenv("RUNNER_OS"), os.Getenv("RUNNER_ARCH")
+	os.Setenv("RUNNER_OS", osName)
+	os.Setenv("RUNNER_ARCH", arch)
+	return func() {
+		os.Setenv("RUNNER_OS", previousOS)
+		os.Setenv("RUNNER_ARCH", previousArch)
+	}
+}
+
+func TestPlatformURL(t *testing.T) {
+	const template = "https://example.com/tool-{os}-{arch}.{ext}"
+	defer setRunner("Linux", "X64")()
+	assert.Equal(t, "https://example.com/tool-linux-amd64.tar.gz", cache.PlatformURL(template, nil))
+	assert.Equal(t, "https://example.com/tool-Linux-x86_64.tgz", cache.PlatformURL(template, map[string]string{"linux": "Linux", "amd64": "x86_64", "tar.gz": "tgz"}))
+
+	setRunner("Windows", "ARM64")
+	assert.Equal(t, "https://example.com/tool-windows-arm64.zip", cache.PlatformURL(template, nil))
+
+	setRunner("macOS", "ARM64")
+	assert.Equal(t, "https://example.com/tool-darwin-arm64.tar.gz", cache.PlatformURL(template, nil))
+}
+
+func TestDownloadToolForPlatform(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(r.URL.Path)) }))
+	defer s.Close()
+	defer setRunner("Linux", "X64")()
+	testID := uuid.New().String()
+	tempDir := "./temp-" + testID
+	defer os.RemoveAll(tempDir)
+	cache.SetTempDir(tempDir)
+
+	f, err := cache.DownloadToolForPlatform(s.URL+"/tool-{os}-{arch}.{ext}", &cache.DownloadToolOptions{PlatformOverrides: map[string]string{"amd64": "x64"}})
+	assert.NoError(t, err)
+	b, err := ioutil.ReadFile(f)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("/tool-%s-%s.%s", "linux", "x64", "tar.gz"), string(b))
+}
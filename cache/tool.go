@@ -23,6 +23,8 @@ const (
 type DownloadToolOptions struct {
 	Destination string
 	FileMode    os.FileMode
+	// PlatformOverrides replaces platform names expanded by DownloadToolForPlatform
+	PlatformOverrides map[string]string
 }
 
 // CacheOptions defines the available options for tool and file caching
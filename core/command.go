@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -38,7 +39,18 @@ func Issue(kind string, message ...string) {
 
 // IssueCommand displays a typed message with properties following github actions interface.
 // see https://github.com/actions/toolkit/blob/e69833ed16500afaa7d137a9cf6da76fb8fb54da/packages/core/src/command.ts#L19
+// Secrets registered with SetSecret are redacted from the message and properties of every command but add-mask
 func IssueCommand(kind string, properties map[string]string, message string) {
+	if kind != "add-mask" {
+		if properties != nil {
+			redacted := make(map[string]string, len(properties))
+			for k, v := range properties {
+				redacted[k] = redact(v)
+			}
+			properties = redacted
+		}
+		message = redact(message)
+	}
 	c := &command{kind, properties, message}
 	stdoutSetter.Lock()
 	fmt.Fprintln(stdout, c.String())
@@ -69,8 +81,14 @@ type command struct {
 func (c *command) String() string {
 	s := cmdString + c.command
 	sep := " "
-	for key, value := range c.properties {
-		s += sep + key + "=" + escape(value)
+	// sort properties for commands to be reproducible
+	keys := make([]string, 0, len(c.properties))
+	for key := range c.properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s += sep + key + "=" + escape(c.properties[key])
 		sep = ","
 	}
 	return s + cmdString + escape(c.message)
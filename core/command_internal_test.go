@@ -31,5 +31,12 @@ func TestIssueCommand(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("::some%0D%0A%25message\n$"), b.String())
 	assert.Regexp(t, regexp.MustCompile("^::hello "), b.String())
 	assert.Len(t, strings.Split(b.String(), ","), 2)
+	assert.Regexp(t, regexp.MustCompile("^::hello other=value,some="), b.String(), "properties must be sorted")
+}
 
+func TestNotice(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	stdout = b
+	Noticef("deployed %s", "v1.0.0")
+	assert.Equal(t, "::notice::deployed v1.0.0\n", b.String())
 }
@@ -18,6 +18,9 @@ var (
 	status       = StatusSuccess
 	statusAccess = &sync.Mutex{}
 	lookupEnv    = os.LookupEnv
+
+	secrets       = []string{}
+	secretsAccess = &sync.Mutex{}
 )
 
 // ExportVariable sets the environment varaible name (for this action and future actions)
@@ -29,11 +32,27 @@ func ExportVariable(name, value string) {
 	os.Setenv(name, value)
 }
 
-// SetSecret registers a secret which will get masked from logs
+// SetSecret registers a secret which will get masked from logs.
+// Messages written by this package are also redacted, for the secret not to leak in structured logs
 func SetSecret(secret string) {
+	if secret != "" {
+		secretsAccess.Lock()
+		secrets = append(secrets, secret)
+		secretsAccess.Unlock()
+	}
 	Issue("add-mask", secret)
 }
 
+// redact replaces secrets registered with SetSecret in message with ***, as GitHub does
+func redact(message string) string {
+	secretsAccess.Lock()
+	defer secretsAccess.Unlock()
+	for _, secret := range secrets {
+		message = strings.Replace(message, secret, "***", -1)
+	}
+	return message
+}
+
 // AddPath prepends inputPath to the PATH (for this action and future actions)
 func AddPath(path string) {
 	if err := issueFileCommand("PATH", path); err != nil {
@@ -75,14 +94,29 @@ func SetFailedf(format string, args ...interface{}) {
 
 // SetFailed sets the action status to failed and sets an error message
 func SetFailed(message string) {
+	SetFailedWithProperties(nil, message)
+}
+
+// SetFailedWithProperties sets the action status to failed and sets an error message displayed with properties,
+// for example the file and line of an annotation
+func SetFailedWithProperties(properties map[string]string, message string) {
 	statusAccess.Lock()
 	status = StatusFailed
 	statusAccess.Unlock()
-	Error(message)
+	logJSON("error", message)
+	IssueCommand("error", properties, message)
+}
+
+// Status returns StatusFailed once the action has been marked as failed, StatusSuccess otherwise
+func Status() int {
+	statusAccess.Lock()
+	defer statusAccess.Unlock()
+	return status
 }
 
 // Debug writes debug message to user log
 func Debug(message string) {
+	logJSON("debug", message)
 	Issue("debug", message)
 }
 
@@ -93,6 +127,7 @@ func Debugf(format string, args ...interface{}) {
 
 // Error adds an error issue
 func Error(message string) {
+	logJSON("error", message)
 	Issue("error", message)
 }
 
@@ -103,6 +138,7 @@ func Errorf(format string, args ...interface{}) {
 
 // Warning adds a warning issue
 func Warning(message string) {
+	logJSON("warning", message)
 	Issue("warning", message)
 }
 
@@ -111,9 +147,20 @@ func Warningf(format string, args ...interface{}) {
 	Warning(fmt.Sprintf(format, args...))
 }
 
+// Notice adds a notice issue
+func Notice(message string) {
+	logJSON("notice", message)
+	Issue("notice", message)
+}
+
+// Noticef adds a notice issue
+func Noticef(format string, args ...interface{}) {
+	Notice(fmt.Sprintf(format, args...))
+}
+
 // Info writes the message on the console
 func Info(message string) {
-	fmt.Println(message)
+	fmt.Println(redact(message))
 }
 
 // Infof writes debug message to user log
@@ -141,16 +188,50 @@ func Group(name string, f func()) func() {
 }
 
 // SaveState saves state for current action, the state can only be retrieved by this action's post job execution.
-func SaveState(name, value string) {
-	IssueCommand("save-state", map[string]string{"name": name}, value)
+// The state is written to the GITHUB_STATE file, or using the legacy save-state command when the runner does not provide it
+func SaveState(name, value string) error {
+	ok, err := appendKeyValues("STATE", map[string]string{name: value}, formatKeyValue)
+	if !ok {
+		IssueCommand("save-state", map[string]string{"name": name}, value)
+	}
+	return err
 }
 
 // GetState gets the value of an state set by this action's main execution.
 func GetState(name string) string {
-	return os.Getenv("STATE_" + name)
+	v, _ := lookupEnv("STATE_" + name)
+	return v
+}
+
+// isPostState is the state the official toolkit uses to tell the main and post phases of an action apart
+const isPostState = "isPost"
+
+// InstallPostHandler flags the current phase as the main one: the runner exposes states saved by the main phase to the
+// post phase only, as STATE_isPost. It must be called early in the main phase of actions declaring a post step
+func InstallPostHandler() error {
+	if IsPost() {
+		return nil
+	}
+	return SaveState(isPostState, "true")
+}
+
+// IsPost returns whether the action is running its post phase, as long as its main phase called InstallPostHandler.
+// GitHub does not expose the phase, this follows the official toolkit convention relying on the isPost state
+func IsPost() bool {
+	return GetState(isPostState) == "true"
 }
 
 // IsDebug returns whether the github actions is currently under debug
 func IsDebug() bool {
 	return os.Getenv("RUNNER_DEBUG") == "1"
 }
+
+// RunnerOS returns the operating system of the runner executing the job: Linux, Windows, or macOS
+func RunnerOS() string {
+	return os.Getenv("RUNNER_OS")
+}
+
+// RunnerArch returns the architecture of the runner executing the job: X86, X64, ARM, or ARM64
+func RunnerArch() string {
+	return os.Getenv("RUNNER_ARCH")
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// position returns the 1-based line and column of the character read last when a JSON decoder reached offset
+func position(s string, offset int64) (line, column int) {
+	if offset > int64(len(s)) {
+		offset = int64(len(s))
+	}
+	if offset > 0 {
+		offset--
+	}
+	before := s[:offset]
+	line = strings.Count(before, "\n") + 1
+	column = len(before) - strings.LastIndex(before, "\n")
+	return line, column
+}
+
+// GetJSONInput parses the JSON value of an input into out.
+// When the input is not set or empty, out is left unchanged and no error is returned
+func GetJSONInput(name string, out interface{}) error {
+	v, _ := GetInput(name)
+	if v == "" {
+		return nil
+	}
+	err := json.Unmarshal([]byte(v), out)
+	if err == nil {
+		return nil
+	}
+	var offset int64 = -1
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	}
+	if offset >= 0 {
+		line, column := position(v, offset)
+		return fmt.Errorf("failed to parse input %s as JSON at line %d, column %d: %v", name, line, column, err)
+	}
+	return fmt.Errorf("failed to parse input %s as JSON: %v", name, err)
+}
+
+// GetYAMLInput parses the YAML value of an input into out, errors report the offending line.
+// When the input is not set or empty, out is left unchanged and no error is returned
+func GetYAMLInput(name string, out interface{}) error {
+	v, _ := GetInput(name)
+	if v == "" {
+		return nil
+	}
+	if err := yaml.Unmarshal([]byte(v), out); err != nil {
+		return fmt.Errorf("failed to parse input %s as YAML: %v", name, err)
+	}
+	return nil
+}
+
+// InputSpec describes the constraints an input must satisfy, see ValidateInputs
+type InputSpec struct {
+	// Required inputs must be set to a non empty value, unless they have a Default
+	Required bool
+	// Default is used when the input is not set or empty
+	Default string
+	// Enum, when not empty, lists the accepted values
+	Enum []string
+	// Pattern, when set, is a regular expression the value must match
+	Pattern string
+	// Type is one of string, the default, bool, int, number or duration
+	Type string
+}
+
+// InputValidationError lists all the violations found by ValidateInputs
+type InputValidationError struct {
+	Violations []string
+}
+
+func (e *InputValidationError) Error() string {
+	return fmt.Sprintf("invalid inputs: %s", strings.Join(e.Violations, "; "))
+}
+
+func validateType(t, v string) error {
+	var err error
+	switch t {
+	case "", "string":
+	case "bool":
+		_, err = strconv.ParseBool(v)
+	case "int":
+		_, err = strconv.Atoi(v)
+	case "number":
+		_, err = strconv.ParseFloat(v, 64)
+	case "duration":
+		_, err = time.ParseDuration(v)
+	default:
+		return fmt.Errorf("unknown type %s", t)
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", v, t)
+	}
+	return nil
+}
+
+func validateInput(name string, spec InputSpec) (string, []string) {
+	v, _ := GetInput(name)
+	if v == "" {
+		v = spec.Default
+	}
+	if v == "" {
+		if spec.Required {
+			return v, []string{fmt.Sprintf("input %s is required", name)}
+		}
+		return v, nil
+	}
+	violations := []string{}
+	if len(spec.Enum) > 0 {
+		found := false
+		for _, e := range spec.Enum {
+			found = found || e == v
+		}
+		if !found {
+			violations = append(violations, fmt.Sprintf("input %s must be one of %s, got %q", name, strings.Join(spec.Enum, ", "), v))
+		}
+	}
+	if spec.Pattern != "" {
+		exp, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("input %s has an invalid pattern %s: %v", name, spec.Pattern, err))
+		} else if !exp.MatchString(v) {
+			violations = append(violations, fmt.Sprintf("input %s must match %s, got %q", name, spec.Pattern, v))
+		}
+	}
+	if err := validateType(spec.Type, v); err != nil {
+		violations = append(violations, fmt.Sprintf("input %s: %v", name, err))
+	}
+	return v, violations
+}
+
+// ValidateInputs reads the inputs described by schema, applies their defaults and checks their constraints.
+// All violations are reported at once in an *InputValidationError, sorted by input name
+func ValidateInputs(schema map[string]InputSpec) (map[string]string, error) {
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := map[string]string{}
+	violations := []string{}
+	for _, name := range names {
+		v, errs := validateInput(name, schema[name])
+		values[name] = v
+		violations = append(violations, errs...)
+	}
+	if len(violations) > 0 {
+		return nil, &InputValidationError{Violations: violations}
+	}
+	return values, nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type structuredConfig struct {
+	Name    string   `json:"name" yaml:"name"`
+	Retries int      `json:"retries" yaml:"retries"`
+	Labels  []string `json:"labels" yaml:"labels"`
+}
+
+func mockInputs(inputs map[string]string) func() {
+	lookupEnv = func(name string) (string, bool) {
+		v, ok := inputs[name]
+		return v, ok
+	}
+	return func() { lookupEnv = os.LookupEnv }
+}
+
+func TestGetJSONInput(t *testing.T) {
+	defer mockInputs(map[string]string{
+		"INPUT_CONFIG":  `{"name": "build", "retries": 3, "labels": ["ci", "go"]}`,
+		"INPUT_INVALID": "{\n  \"name\": \"build\",\n  \"retries\": three\n}",
+		"INPUT_TYPE":    `{"retries": "three"}`,
+		"INPUT_EMPTY":   "",
+	})()
+
+	config := structuredConfig{}
+	assert.NoError(t, GetJSONInput("config", &config))
+	assert.Equal(t, structuredConfig{Name: "build", Retries: 3, Labels: []string{"ci", "go"}}, config)
+
+	err := GetJSONInput("invalid", &config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "input invalid")
+		assert.Contains(t, err.Error(), "line 3, column 15")
+	}
+	err = GetJSONInput("type", &config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "line 1")
+	}
+
+	t.Run("empty and unset inputs leave the value unchanged", func(t *testing.T) {
+		config := structuredConfig{Name: "default"}
+		assert.NoError(t, GetJSONInput("empty", &config))
+		assert.NoError(t, GetJSONInput("unset", &config))
+		assert.Equal(t, structuredConfig{Name: "default"}, config)
+	})
+}
+
+func TestGetYAMLInput(t *testing.T) {
+	defer mockInputs(map[string]string{
+		"INPUT_CONFIG":  "name: build\nretries: 3\nlabels:\n  - ci\n  - go\n",
+		"INPUT_INVALID": "name: build\nretries: [3\n",
+	})()
+
+	config := structuredConfig{Name: "default"}
+	assert.NoError(t, GetYAMLInput("unset", &config))
+	assert.Equal(t, "default", config.Name)
+	assert.NoError(t, GetYAMLInput("config", &config))
+	assert.Equal(t, structuredConfig{Name: "build", Retries: 3, Labels: []string{"ci", "go"}}, config)
+
+	err := GetYAMLInput("invalid", &config)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "line")
+	}
+}
+
+func TestValidateInputs(t *testing.T) {
+	defer mockInputs(map[string]string{
+		"INPUT_LEVEL":   "verbose",
+		"INPUT_VERSION": "v1.2",
+		"INPUT_RETRIES": "three",
+		"INPUT_TIMEOUT": "",
+		"INPUT_NAME":    "build",
+	})()
+
+	values, err := ValidateInputs(map[string]InputSpec{
+		"name":    {Required: true, Pattern: "^[a-z]+$"},
+		"timeout": {Default: "5m", Type: "duration"},
+		"dry-run": {Type: "bool"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "build", "timeout": "5m", "dry-run": ""}, values)
+
+	values, err = ValidateInputs(map[string]InputSpec{
+		"token":   {Required: true},
+		"level":   {Enum: []string{"debug", "info"}},
+		"version": {Pattern: `^v\d+\.\d+\.\d+$`},
+		"retries": {Type: "int"},
+		"name":    {Required: true},
+	})
+	assert.Nil(t, values)
+	if assert.IsType(t, &InputValidationError{}, err) {
+		assert.Equal(t, []string{
+			`input level must be one of debug, info, got "verbose"`,
+			`input retries: "three" is not a valid int`,
+			"input token is required",
+			`input version must match ^v\d+\.\d+\.\d+$, got "v1.2"`,
+		}, err.(*InputValidationError).Violations)
+		assert.Contains(t, err.Error(), "input token is required; input version must match")
+	}
+}
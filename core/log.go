@@ -0,0 +1,78 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const jsonLogsEnv = "ACTIONS_TOOLKIT_JSON_LOGS"
+
+var (
+	stderr       io.Writer = os.Stderr
+	stderrSetter sync.Mutex
+	jsonLogs     *bool
+)
+
+// SetStderr sets the writer receiving structured logs
+func SetStderr(w io.Writer) {
+	stderrSetter.Lock()
+	stderr = w
+	stderrSetter.Unlock()
+}
+
+// SetJSONLogging enables or disables structured logs, overriding the ACTIONS_TOOLKIT_JSON_LOGS environment variable.
+// When enabled, debug, warning and error messages are also written to stderr as JSON records,
+// workflow commands are still written to stdout for GitHub to parse them.
+func SetJSONLogging(enabled bool) {
+	stderrSetter.Lock()
+	jsonLogs = &enabled
+	stderrSetter.Unlock()
+}
+
+type jsonRecord struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	TS    string `json:"ts"`
+}
+
+// caller returns the location of the first caller outside of this package
+func caller() (string, int) {
+	pc := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/actions-go/toolkit/core.") || strings.HasSuffix(frame.File, "_test.go") {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+func logJSON(level, message string) {
+	stderrSetter.Lock()
+	defer stderrSetter.Unlock()
+	enabled := strings.ToLower(os.Getenv(jsonLogsEnv)) == "true"
+	if jsonLogs != nil {
+		enabled = *jsonLogs
+	}
+	if !enabled {
+		return
+	}
+	file, line := caller()
+	json.NewEncoder(stderr).Encode(jsonRecord{
+		Level: level,
+		Msg:   redact(message),
+		File:  file,
+		Line:  line,
+		TS:    time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLogging(t *testing.T) {
+	out, errOut := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	SetStdout(out)
+	SetStderr(errOut)
+	defer SetStdout(os.Stdout)
+	defer SetStderr(os.Stderr)
+	defer func() { jsonLogs = nil }()
+
+	Warning("not structured by default")
+	assert.Equal(t, "::warning::not structured by default\n", out.String())
+	assert.Equal(t, "", errOut.String())
+
+	out.Reset()
+	SetJSONLogging(true)
+	Debugf("hello %s", "world")
+	Warning("some warning")
+	Errorf("some error")
+	assert.Equal(t, "::debug::hello world\n::warning::some warning\n::error::some error\n", out.String())
+
+	lines := strings.Split(strings.TrimSpace(errOut.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		record := jsonRecord{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		assert.Equal(t, "debug", record.Level)
+		assert.Equal(t, "hello world", record.Msg)
+		assert.True(t, strings.HasSuffix(record.File, "log_test.go"), record.File)
+		assert.NotZero(t, record.Line)
+		_, err := time.Parse(time.RFC3339Nano, record.TS)
+		assert.NoError(t, err)
+
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+		assert.Equal(t, "warning", record.Level)
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &record))
+		assert.Equal(t, "error", record.Level)
+	}
+
+	t.Run("structured logs can be enabled from the environment", func(t *testing.T) {
+		jsonLogs = nil
+		errOut.Reset()
+		os.Setenv(jsonLogsEnv, "true")
+		defer os.Unsetenv(jsonLogsEnv)
+		Error("from env")
+		assert.Contains(t, errOut.String(), `"msg":"from env"`)
+	})
+}
+
+func TestJSONLoggingRedactsSecrets(t *testing.T) {
+	out, errOut := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	SetStdout(out)
+	SetStderr(errOut)
+	defer SetStdout(os.Stdout)
+	defer SetStderr(os.Stderr)
+	defer func() { jsonLogs = nil }()
+	defer func(previous []string) { secrets = previous }(secrets)
+
+	SetJSONLogging(true)
+	SetSecret("p4ssw0rd")
+	Debug("logging in with p4ssw0rd")
+	assert.Equal(t, "::add-mask::p4ssw0rd\n::debug::logging in with ***\n", out.String())
+	record := jsonRecord{}
+	assert.NoError(t, json.Unmarshal(errOut.Bytes(), &record))
+	assert.Equal(t, "logging in with ***", record.Msg)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionInput describes an input declared in the action metadata
+type ActionInput struct {
+	Description        string `yaml:"description"`
+	Required           bool   `yaml:"required"`
+	Default            string `yaml:"default"`
+	DeprecationMessage string `yaml:"deprecationMessage"`
+}
+
+// ActionOutput describes an output declared in the action metadata
+type ActionOutput struct {
+	Description string `yaml:"description"`
+	// Value is only set for composite actions
+	Value string `yaml:"value"`
+}
+
+// ActionRuns describes how the action is executed
+type ActionRuns struct {
+	Using      string                   `yaml:"using"`
+	Main       string                   `yaml:"main"`
+	Pre        string                   `yaml:"pre"`
+	Post       string                   `yaml:"post"`
+	Image      string                   `yaml:"image"`
+	Entrypoint string                   `yaml:"entrypoint"`
+	Args       []string                 `yaml:"args"`
+	Env        map[string]string        `yaml:"env"`
+	Steps      []map[string]interface{} `yaml:"steps"`
+}
+
+// ActionYAML is the content of the action metadata file, action.yml
+type ActionYAML struct {
+	Name        string                  `yaml:"name"`
+	Description string                  `yaml:"description"`
+	Author      string                  `yaml:"author"`
+	Inputs      map[string]ActionInput  `yaml:"inputs"`
+	Outputs     map[string]ActionOutput `yaml:"outputs"`
+	Runs        ActionRuns              `yaml:"runs"`
+}
+
+// ActionPath returns the path where the action files are located, only set for composite actions
+func ActionPath() string {
+	return os.Getenv("GITHUB_ACTION_PATH")
+}
+
+// ActionMetadata reads the action.yml, or action.yaml, file located in ActionPath
+func ActionMetadata() (*ActionYAML, error) {
+	dir := ActionPath()
+	if dir == "" {
+		return nil, fmt.Errorf("unable to find action metadata: GITHUB_ACTION_PATH is not set")
+	}
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		metadata := &ActionYAML{}
+		if err := yaml.Unmarshal(b, metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", filepath.Join(dir, name), err)
+		}
+		return metadata, nil
+	}
+	return nil, fmt.Errorf("unable to find action.yml or action.yaml in %s", dir)
+}
+
+// DefaultFor returns the default value declared for an input.
+// It can be used as a fallback when an input is not set, for example:
+//
+//	def, _ := metadata.DefaultFor(name)
+//	value := GetInputOrDefault(name, def)
+func (a *ActionYAML) DefaultFor(input string) (string, bool) {
+	for name, spec := range a.Inputs {
+		if strings.EqualFold(name, input) {
+			return spec.Default, spec.Default != ""
+		}
+	}
+	return "", false
+}
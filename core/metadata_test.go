@@ -0,0 +1,70 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleActionYAML = `name: hello world
+description: greets someone
+inputs:
+  who-to-greet:
+    description: who to greet
+    required: true
+    default: World
+  greeting:
+    description: the greeting
+outputs:
+  time:
+    description: the time we greeted you
+runs:
+  using: docker
+  image: Dockerfile
+  args:
+    - ${{ inputs.who-to-greet }}
+`
+
+func TestActionMetadata(t *testing.T) {
+	dir, err := ioutil.TempDir("", "action")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	previous := os.Getenv("GITHUB_ACTION_PATH")
+	defer os.Setenv("GITHUB_ACTION_PATH", previous)
+
+	os.Setenv("GITHUB_ACTION_PATH", "")
+	_, err = ActionMetadata()
+	assert.Error(t, err)
+
+	os.Setenv("GITHUB_ACTION_PATH", dir)
+	assert.Equal(t, dir, ActionPath())
+	_, err = ActionMetadata()
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "action.yaml"), []byte(sampleActionYAML), 0644))
+	metadata, err := ActionMetadata()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", metadata.Name)
+	assert.True(t, metadata.Inputs["who-to-greet"].Required)
+	assert.False(t, metadata.Inputs["greeting"].Required)
+	assert.Equal(t, "the time we greeted you", metadata.Outputs["time"].Description)
+	assert.Equal(t, "docker", metadata.Runs.Using)
+	assert.Equal(t, []string{"${{ inputs.who-to-greet }}"}, metadata.Runs.Args)
+
+	v, ok := metadata.DefaultFor("Who-To-Greet")
+	assert.True(t, ok)
+	assert.Equal(t, "World", v)
+	_, ok = metadata.DefaultFor("greeting")
+	assert.False(t, ok)
+	_, ok = metadata.DefaultFor("undeclared")
+	assert.False(t, ok)
+
+	t.Run("malformed metadata returns an error", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "action.yml"), []byte("inputs: [not a map"), 0644))
+		_, err := ActionMetadata()
+		assert.Error(t, err)
+	})
+}
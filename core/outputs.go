@@ -0,0 +1,143 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// newDelimiter returns a random heredoc delimiter so values can't accidentally terminate it
+func newDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
+
+// formatKeyValue formats a value for the GITHUB_OUTPUT and GITHUB_STATE files, multiline values use the heredoc format
+func formatKeyValue(name, value string) (string, error) {
+	if !strings.ContainsAny(value, "\r\n") {
+		if err := validateKeyName(name); err != nil {
+			return "", err
+		}
+		return name + "=" + value + EOF, nil
+	}
+	return formatHeredoc(name, value)
+}
+
+// validateKeyName rejects names that would allow writing other keys to a command file
+func validateKeyName(name string) error {
+	if name == "" || strings.ContainsAny(name, "\r\n=") || strings.Contains(name, "<<") {
+		return fmt.Errorf("invalid name %q, it must not be empty nor contain line breaks, = or <<", name)
+	}
+	return nil
+}
+
+// formatHeredoc formats a value using the heredoc format, with a random delimiter that does not appear in the value
+func formatHeredoc(name, value string) (string, error) {
+	if err := validateKeyName(name); err != nil {
+		return "", err
+	}
+	for attempt := 0; attempt < 3; attempt++ {
+		delimiter, err := newDelimiter()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate delimiter for %s: %v", name, err)
+		}
+		if !strings.Contains(value, delimiter) {
+			return fmt.Sprintf("%s<<%s%s%s%s%s%s", name, delimiter, EOF, value, EOF, delimiter, EOF), nil
+		}
+	}
+	return "", fmt.Errorf("failed to generate a delimiter not found in the value of %s", name)
+}
+
+// appendKeyValues appends values, sorted by name and formatted with format, to the file referenced by the GITHUB_<command> variable.
+// ok is false when the runner does not provide the file
+func appendKeyValues(command string, values map[string]string, format func(name, value string) (string, error)) (ok bool, err error) {
+	path, ok := lookupEnv("GITHUB_" + command)
+	if !ok || path == "" {
+		return false, nil
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	content := strings.Builder{}
+	for _, name := range names {
+		line, err := format(name, values[name])
+		if err != nil {
+			return true, err
+		}
+		content.WriteString(line)
+	}
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return true, fmt.Errorf("failed to open GITHUB_%s file: %v", command, err)
+	}
+	defer fd.Close()
+	if _, err := fd.WriteString(content.String()); err != nil {
+		return true, fmt.Errorf("failed to write to GITHUB_%s file: %v", command, err)
+	}
+	return true, nil
+}
+
+// SetOutputs sets several outputs at once using the GITHUB_OUTPUT file, outputs are written sorted by name.
+// When the runner does not provide the file, outputs are set using the legacy set-output command
+func SetOutputs(values map[string]string) error {
+	ok, err := appendKeyValues("OUTPUT", values, formatKeyValue)
+	if ok {
+		return err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		SetOutput(name, values[name])
+	}
+	return nil
+}
+
+// SetMultilineOutput sets an output using the heredoc format of the GITHUB_OUTPUT file, whatever the value contains.
+// The delimiter is random and checked not to appear in the value, so values can't end the block early and set other outputs.
+// When the runner does not provide the file, the output is set using the legacy set-output command
+func SetMultilineOutput(name, value string) error {
+	ok, err := appendKeyValues("OUTPUT", map[string]string{name: value}, formatHeredoc)
+	if !ok {
+		SetOutput(name, value)
+		return nil
+	}
+	return err
+}
+
+// SetOutputsStruct sets an output for each field of v tagged with `action:"output-name"`.
+// v must be a struct or a pointer to a struct, non string fields are converted using fmt.Sprint. Unexported fields are skipped
+func SetOutputsStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return fmt.Errorf("unable to set outputs from a nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("unable to set outputs from %T, a struct is expected", v)
+	}
+	values := map[string]string{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Tag.Get("action")
+		// unexported fields can't be read through reflection
+		if name == "" || name == "-" || rt.Field(i).PkgPath != "" {
+			continue
+		}
+		values[name] = fmt.Sprint(rv.Field(i).Interface())
+	}
+	return SetOutputs(values)
+}
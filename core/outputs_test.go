@@ -0,0 +1,161 @@
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCommandFile creates the GITHUB_<command> file, other variables are read from env
+func mockCommandFile(t *testing.T, command string, env map[string]string) (string, func()) {
+	fd, err := ioutil.TempFile("", "file-command")
+	require.NoError(t, err)
+	fd.Close()
+	lookupEnv = func(name string) (string, bool) {
+		if name == "GITHUB_"+command {
+			return fd.Name(), true
+		}
+		v, ok := env[name]
+		return v, ok
+	}
+	return fd.Name(), func() {
+		lookupEnv = os.LookupEnv
+		os.Remove(fd.Name())
+	}
+}
+
+func TestSetOutputsStruct(t *testing.T) {
+	path, restore := mockCommandFile(t, "OUTPUT", nil)
+	defer restore()
+
+	v := struct {
+		Version   string `action:"version"`
+		Count     int    `action:"count"`
+		Published bool   `action:"published"`
+		Notes     string `action:"notes"`
+		Ignored   string
+		Skipped   string `action:"-"`
+		private   string `action:"private"`
+	}{"v1.2.3", 42, true, "first line\nsecond line", "ignored", "skipped", "private"}
+	require.NoError(t, SetOutputsStruct(&v))
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^count=42
+notes<<(ghadelimiter_[0-9a-f]+)
+first line
+second line
+ghadelimiter_[0-9a-f]+
+published=true
+version=v1.2.3
+$`), string(b))
+	assert.NotContains(t, string(b), "ignored")
+	assert.NotContains(t, string(b), "skipped")
+	assert.NotContains(t, string(b), "private", "unexported fields are skipped")
+
+	assert.Error(t, SetOutputsStruct("not a struct"))
+	assert.Error(t, SetOutputsStruct((*struct{})(nil)))
+}
+
+func TestSetOutputs(t *testing.T) {
+	path, restore := mockCommandFile(t, "OUTPUT", nil)
+	defer restore()
+
+	require.NoError(t, SetOutputs(map[string]string{"b": "2", "a": "1"}))
+	require.NoError(t, SetOutputs(map[string]string{"c": "3"}))
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a=1\nb=2\nc=3\n", string(b))
+
+	t.Run("without output file the legacy command is used", func(t *testing.T) {
+		lookupEnv = func(name string) (string, bool) { return "", false }
+		out := bytes.NewBuffer(nil)
+		stdout = out
+		defer func() { stdout = os.Stdout }()
+		require.NoError(t, SetOutputs(map[string]string{"a": "1"}))
+		assert.Equal(t, "::set-output name=a::1\n", out.String())
+	})
+}
+
+func TestSetMultilineOutput(t *testing.T) {
+	path, restore := mockCommandFile(t, "OUTPUT", nil)
+	defer restore()
+
+	require.NoError(t, SetMultilineOutput("script", "cat <<EOF\nhello\nEOF\nother=injected"))
+	require.NoError(t, SetMultilineOutput("windows", "first\r\nsecond"))
+	require.NoError(t, SetMultilineOutput("single", "one line"))
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	match := regexp.MustCompile("^script<<(ghadelimiter_[0-9a-f]+)\ncat <<EOF\nhello\nEOF\nother=injected\n(ghadelimiter_[0-9a-f]+)\n" +
+		"windows<<(ghadelimiter_[0-9a-f]+)\nfirst\r\nsecond\n(ghadelimiter_[0-9a-f]+)\n" +
+		"single<<(ghadelimiter_[0-9a-f]+)\none line\n(ghadelimiter_[0-9a-f]+)\n$").FindStringSubmatch(string(b))
+	if assert.Len(t, match, 7, string(b)) {
+		assert.Equal(t, match[1], match[2])
+		assert.Equal(t, match[3], match[4])
+		assert.NotEqual(t, match[1], match[3], "delimiters must be unique")
+	}
+
+	t.Run("names can't inject other outputs", func(t *testing.T) {
+		for _, name := range []string{"", "a\nb", "a=b", "a<<EOF"} {
+			assert.Error(t, SetMultilineOutput(name, "value"), name)
+			assert.Error(t, SetOutputs(map[string]string{name: "value"}), name)
+		}
+	})
+
+	t.Run("without output file the legacy command is used", func(t *testing.T) {
+		lookupEnv = func(name string) (string, bool) { return "", false }
+		out := bytes.NewBuffer(nil)
+		stdout = out
+		defer func() { stdout = os.Stdout }()
+		require.NoError(t, SetMultilineOutput("a", "1\n2"))
+		assert.Equal(t, "::set-output name=a::1%0A2\n", out.String())
+	})
+}
+
+func TestSaveState(t *testing.T) {
+	path, restore := mockCommandFile(t, "STATE", map[string]string{"STATE_started": "2020-06-01T10:00:00Z"})
+	defer restore()
+
+	require.NoError(t, SaveState("started", "2020-06-01T10:00:00Z"))
+	require.NoError(t, SaveState("paths", "a\nb"))
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile("^started=2020-06-01T10:00:00Z\npaths<<(ghadelimiter_[0-9a-f]+)\na\nb\nghadelimiter_[0-9a-f]+\n$"), string(b))
+
+	assert.Equal(t, "2020-06-01T10:00:00Z", GetState("started"), "the runner exposes saved states to the post step")
+	assert.Equal(t, "", GetState("missing"))
+
+	t.Run("without state file the legacy command is used", func(t *testing.T) {
+		lookupEnv = func(name string) (string, bool) { return "", false }
+		out := bytes.NewBuffer(nil)
+		stdout = out
+		defer func() { stdout = os.Stdout }()
+		require.NoError(t, SaveState("started", "now"))
+		assert.Equal(t, "::save-state name=started::now\n", out.String())
+	})
+}
+
+func TestIsPost(t *testing.T) {
+	env := map[string]string{}
+	path, restore := mockCommandFile(t, "STATE", env)
+	defer restore()
+
+	assert.False(t, IsPost(), "without state, the main phase is running")
+	require.NoError(t, InstallPostHandler())
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "isPost=true\n", string(b))
+
+	// the runner exposes the saved state to the post phase
+	env["STATE_isPost"] = "true"
+	assert.True(t, IsPost())
+	require.NoError(t, InstallPostHandler())
+	b, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "isPost=true\n", string(b), "the post phase must not save the state again")
+}
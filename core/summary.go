@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+)
+
+// Summary builds the markdown job summary displayed on the workflow run page.
+// Content is buffered until Write is called
+type Summary struct {
+	buffer strings.Builder
+}
+
+// NewSummary returns an empty summary
+func NewSummary() *Summary {
+	return &Summary{}
+}
+
+// AddRaw appends raw markdown to the summary
+func (s *Summary) AddRaw(text string) *Summary {
+	s.buffer.WriteString(text)
+	return s
+}
+
+// AddTable appends the rendered table to the summary
+func (s *Summary) AddTable(t *TableBuilder) *Summary {
+	return s.AddRaw(t.Render() + "\n")
+}
+
+// addDetails appends a details element, the blank lines around content let GitHub render it as markdown
+func (s *Summary) addDetails(summary, content string, open bool) *Summary {
+	tag := "<details>"
+	if open {
+		tag = "<details open>"
+	}
+	return s.AddRaw(fmt.Sprintf("%s<summary>%s</summary>\n\n%s\n\n</details>\n", tag, html.EscapeString(summary), strings.Trim(content, "\n")))
+}
+
+// AddDetails appends a collapsible section titled summary, collapsed by default, holding the content markdown.
+// The summary is displayed as plain text, HTML special characters are escaped
+func (s *Summary) AddDetails(summary, content string) *Summary {
+	return s.addDetails(summary, content, false)
+}
+
+// AddDetailsOpen appends a collapsible section like AddDetails, expanded by default
+func (s *Summary) AddDetailsOpen(summary, content string) *Summary {
+	return s.addDetails(summary, content, true)
+}
+
+// String returns the summary content buffered so far
+func (s *Summary) String() string {
+	return s.buffer.String()
+}
+
+// Write appends the summary to the job summary file and empties the buffer
+func (s *Summary) Write() error {
+	path, ok := lookupEnv("GITHUB_STEP_SUMMARY")
+	if !ok || path == "" {
+		return fmt.Errorf("unable to find summary file GITHUB_STEP_SUMMARY")
+	}
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	if _, err := fd.WriteString(s.buffer.String()); err != nil {
+		return err
+	}
+	s.buffer.Reset()
+	return nil
+}
+
+// TableBuilder builds a markdown table row by row
+type TableBuilder struct {
+	header []string
+	rows   [][]string
+}
+
+// NewTableBuilder returns an empty table
+func NewTableBuilder() *TableBuilder {
+	return &TableBuilder{}
+}
+
+// Header sets the table columns
+func (t *TableBuilder) Header(cols ...string) *TableBuilder {
+	t.header = cols
+	return t
+}
+
+// Row appends a row to the table, rows shorter than the widest one are padded with empty cells
+func (t *TableBuilder) Row(cells ...string) *TableBuilder {
+	t.rows = append(t.rows, cells)
+	return t
+}
+
+func escapeTableCell(cell string) string {
+	cell = strings.Replace(cell, "|", "\\|", -1)
+	cell = strings.Replace(cell, "\r\n", "<br>", -1)
+	return strings.Replace(cell, "\n", "<br>", -1)
+}
+
+func renderTableRow(cells []string, width int) string {
+	s := "|"
+	for i := 0; i < width; i++ {
+		cell := ""
+		if i < len(cells) {
+			cell = escapeTableCell(cells[i])
+		}
+		s += " " + cell + " |"
+	}
+	return s + "\n"
+}
+
+// Render returns the markdown representation of the table
+func (t *TableBuilder) Render() string {
+	width := len(t.header)
+	for _, row := range t.rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	if width == 0 {
+		return ""
+	}
+	s := renderTableRow(t.header, width)
+	s += "|" + strings.Repeat(" --- |", width) + "\n"
+	for _, row := range t.rows {
+		s += renderTableRow(row, width)
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableBuilder(t *testing.T) {
+	assert.Equal(t, "", NewTableBuilder().Render())
+
+	table := NewTableBuilder().
+		Header("name", "result").
+		Row("a|b", "ok").
+		Row("multi\nline", "first\r\nsecond").
+		Row("ragged")
+	assert.Equal(t, `| name | result |
+| --- | --- |
+| a\|b | ok |
+| multi<br>line | first<br>second |
+| ragged |  |
+`, table.Render())
+
+	t.Run("rows wider than the header are padded", func(t *testing.T) {
+		assert.Equal(t, `| a |  |
+| --- | --- |
+| 1 | 2 |
+`, NewTableBuilder().Header("a").Row("1", "2").Render())
+	})
+}
+
+func TestSummaryDetails(t *testing.T) {
+	s := NewSummary().
+		AddDetails("Logs <stderr> & stdout", "```\nsome output\n```\n").
+		AddDetailsOpen("Results", "| a |\n| --- |\n| 1 |")
+	assert.Equal(t, "<details><summary>Logs &lt;stderr&gt; &amp; stdout</summary>\n\n```\nsome output\n```\n\n</details>\n"+
+		"<details open><summary>Results</summary>\n\n| a |\n| --- |\n| 1 |\n\n</details>\n", s.String())
+}
+
+func TestSummary(t *testing.T) {
+	fd, err := ioutil.TempFile("", "summary")
+	assert.NoError(t, err)
+	fd.WriteString("# existing\n")
+	fd.Close()
+	defer os.Remove(fd.Name())
+
+	lookupEnv = func(name string) (string, bool) {
+		assert.Equal(t, "GITHUB_STEP_SUMMARY", name)
+		return fd.Name(), true
+	}
+	defer func() { lookupEnv = os.LookupEnv }()
+
+	s := NewSummary().AddRaw("## results\n").AddTable(NewTableBuilder().Header("a").Row("1"))
+	assert.NoError(t, s.Write())
+	assert.Equal(t, "", s.String())
+	b, err := ioutil.ReadFile(fd.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "# existing\n## results\n| a |\n| --- |\n| 1 |\n\n", string(b))
+
+	lookupEnv = func(name string) (string, bool) { return "", false }
+	assert.Error(t, NewSummary().AddRaw("hello").Write())
+}
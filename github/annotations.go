@@ -0,0 +1,76 @@
+package github
+
+import (
+	"strconv"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// commandLevels maps annotation levels to the workflow command displaying them
+var commandLevels = map[string]string{
+	AnnotationNotice:  "notice",
+	AnnotationWarning: "warning",
+	AnnotationFailure: "error",
+}
+
+func (a Annotation) commandProperties() map[string]string {
+	properties := map[string]string{}
+	set := func(name, value string) {
+		if value != "" {
+			properties[name] = value
+		}
+	}
+	set("file", a.Path)
+	set("title", a.Title)
+	for name, value := range map[string]int{"line": a.StartLine, "endLine": a.EndLine, "col": a.StartColumn, "endColumn": a.EndColumn} {
+		if value != 0 {
+			properties[name] = strconv.Itoa(value)
+		}
+	}
+	return properties
+}
+
+// Annotate displays annotations in the workflow run using workflow commands, without requiring any token permission.
+// Unlike check run annotations, GitHub limits their number per step, use CreateCheckRun to report many annotations
+func Annotate(annotations ...Annotation) {
+	for _, a := range annotations {
+		level, ok := commandLevels[a.Level]
+		if !ok {
+			level = commandLevels[AnnotationWarning]
+		}
+		core.IssueCommand(level, a.commandProperties(), a.Message)
+	}
+}
+
+// AnnotationOption sets the location of the annotation displayed by Fail
+type AnnotationOption func(*Annotation)
+
+// WithFile anchors the annotation to a file, relative to the repository root
+func WithFile(path string) AnnotationOption {
+	return func(a *Annotation) {
+		a.Path = path
+	}
+}
+
+// WithLine anchors the annotation to a line of the file
+func WithLine(line int) AnnotationOption {
+	return func(a *Annotation) {
+		a.StartLine = line
+	}
+}
+
+// WithTitle sets the title of the annotation
+func WithTitle(title string) AnnotationOption {
+	return func(a *Annotation) {
+		a.Title = title
+	}
+}
+
+// Fail marks the action as failed, like core.SetFailed, and displays message as an error annotation at the location set by options
+func Fail(message string, options ...AnnotationOption) {
+	a := Annotation{Level: AnnotationFailure, Message: message}
+	for _, option := range options {
+		option(&a)
+	}
+	core.SetFailedWithProperties(a.commandProperties(), message)
+}
@@ -0,0 +1,35 @@
+package github
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotate(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	core.SetStdout(b)
+	defer core.SetStdout(os.Stdout)
+
+	Annotate(
+		Annotation{Level: AnnotationNotice, Path: "core/core.go", StartLine: 12, Title: "Deprecation", Message: "use Notice, instead"},
+		Annotation{Level: AnnotationFailure, Path: "main.go", StartLine: 3, EndLine: 5, StartColumn: 2, Message: "build failed"},
+		Annotation{Message: "warnings are the default"},
+	)
+	assert.Equal(t, "::notice file=core/core.go,line=12,title=Deprecation::use Notice%2C instead\n"+
+		"::error col=2,endLine=5,file=main.go,line=3::build failed\n"+
+		"::warning::warnings are the default\n", b.String())
+}
+
+func TestFail(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	core.SetStdout(b)
+	defer core.SetStdout(os.Stdout)
+
+	Fail("go.sum is outdated", WithFile("go.sum"), WithLine(12), WithTitle("Dependencies"))
+	assert.Equal(t, "::error file=go.sum,line=12,title=Dependencies::go.sum is outdated\n", b.String())
+	assert.Equal(t, core.StatusFailed, core.Status())
+}
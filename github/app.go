@@ -0,0 +1,217 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+)
+
+// appTokenRefreshMargin is how long before their expiry installation tokens are renewed
+const appTokenRefreshMargin = 5 * time.Minute
+
+// appAuth is the installation token source registered by UseAppAuth, nil when the action token is used
+var (
+	appAuth       *appTokenSource
+	appAuthAccess sync.Mutex
+)
+
+// appTokenSource mints installation tokens of a GitHub App and renews them before they expire
+type appTokenSource struct {
+	lock           sync.Mutex
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        *url.URL
+	token          string
+	expires        time.Time
+}
+
+// installationToken returns the current installation token, renewing it when it is about to expire
+func (s *appTokenSource) installationToken(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.token != "" && now().Before(s.expires.Add(-appTokenRefreshMargin)) {
+		return s.token, nil
+	}
+	jwt, err := appJWT(s.appID, s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app token: %v", err)
+	}
+	t, expires, err := installationToken(ctx, s.baseURL, jwt, s.installationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to create installation token: %v", err)
+	}
+	MaskValue(t)
+	s.token, s.expires = t, expires
+	return t, nil
+}
+
+// Token implements oauth2.TokenSource
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	t, err := s.installationToken(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: t}, nil
+}
+
+// currentAppAuth returns the token source registered by UseAppAuth, if any
+func currentAppAuth() *appTokenSource {
+	appAuthAccess.Lock()
+	defer appAuthAccess.Unlock()
+	return appAuth
+}
+
+// parseAppPrivateKey parses the PEM private key of a GitHub App, PKCS#1 as generated by GitHub or PKCS#8
+func parseAppPrivateKey(privateKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, fmt.Errorf("the private key must be PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("malformed private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key must be an RSA key, got %T", key)
+	}
+	return rsaKey, nil
+}
+
+// appJWT returns the JSON web token authenticating as the GitHub App appID, valid for 9 minutes.
+// It is issued a minute in the past to allow for clock drift
+func appJWT(appID int64, key *rsa.PrivateKey) (string, error) {
+	encode := func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	issued := now().Add(-time.Minute)
+	unsigned := encode(map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encode(map[string]int64{
+		"iat": issued.Unix(),
+		"exp": issued.Add(10 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// appRequest calls the API authenticating as a GitHub App with its JSON web token, v is decoded from the response when
+// the API answers with the expected status
+func appRequest(ctx context.Context, method string, baseURL *url.URL, path, jwt string, expected int, v interface{}) error {
+	u, err := baseURL.Parse(path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expected {
+		apiErr := struct {
+			Message string `json:"message"`
+		}{}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("unexpected code %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("unexpected code %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// installationToken exchanges a GitHub App JSON web token for a token of the installation installationID
+func installationToken(ctx context.Context, baseURL *url.URL, jwt string, installationID int64) (string, time.Time, error) {
+	t := struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{}
+	err := appRequest(ctx, http.MethodPost, baseURL, fmt.Sprintf("app/installations/%d/access_tokens", installationID), jwt, http.StatusCreated, &t)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return t.Token, t.ExpiresAt, nil
+}
+
+// login returns the login of the bot user the installations of the app act as, its slug followed by [bot]
+func (s *appTokenSource) login(ctx context.Context) (string, error) {
+	jwt, err := appJWT(s.appID, s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app token: %v", err)
+	}
+	app := struct {
+		Slug string `json:"slug"`
+	}{}
+	if err := appRequest(ctx, http.MethodGet, s.baseURL, "app", jwt, http.StatusOK, &app); err != nil {
+		return "", fmt.Errorf("failed to get app %d: %v", s.appID, err)
+	}
+	return app.Slug + "[bot]", nil
+}
+
+// AppInstallationToken creates a token of the installation installationID of the GitHub App appID, authenticating with
+// the PEM private key of the app. The token is masked from the logs
+func AppInstallationToken(appID, installationID int64, privateKeyPEM []byte) TokenSource {
+	return TokenSource{
+		name: fmt.Sprintf("installation %d of app %d", installationID, appID),
+		token: func() (string, error) {
+			key, err := parseAppPrivateKey(privateKeyPEM)
+			if err != nil {
+				return "", err
+			}
+			s := &appTokenSource{appID: appID, installationID: installationID, key: key, baseURL: GitHub.BaseURL}
+			return s.installationToken(context.Background())
+		},
+	}
+}
+
+// UseAppAuth makes the whole action authenticate as the installation installationID of the GitHub App appID.
+// An installation token is created with the PEM private key of the app, masked from the logs, and used by the
+// package client GitHub, which is replaced, and for downloads. Tokens are renewed shortly before they expire.
+// It is meant to be called once, early, before the package client is used concurrently
+func UseAppAuth(ctx context.Context, appID, installationID int64, privateKeyPEM []byte) error {
+	key, err := parseAppPrivateKey(privateKeyPEM)
+	if err != nil {
+		return err
+	}
+	s := &appTokenSource{appID: appID, installationID: installationID, key: key, baseURL: GitHub.BaseURL}
+	if _, err := s.installationToken(ctx); err != nil {
+		return fmt.Errorf("failed to authenticate as installation %d of app %d: %v", installationID, appID, err)
+	}
+	httpClient := NewHTTPClient()
+	httpClient.Transport = &oauth2.Transport{Source: s, Base: httpClient.Transport}
+	client := github.NewClient(httpClient)
+	client.BaseURL, client.UploadURL = GitHub.BaseURL, GitHub.UploadURL
+	appAuthAccess.Lock()
+	appAuth = s
+	appAuthAccess.Unlock()
+	GitHub = client
+	return nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseAppAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+
+	lock := sync.Mutex{}
+	minted := 0
+	authorizations := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		minted++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "ghs_installation_%d", "expires_at": %q}`, minted, now().Add(time.Hour).Format(time.RFC3339))
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		authorizations[r.Header.Get("Authorization")]++
+		fmt.Fprint(w, `{"name": "toolkit"}`)
+	})
+	defer mockGitHub(mux)()
+	defer func() { appAuth = nil }()
+	ctx := context.Background()
+
+	assert.Error(t, UseAppAuth(ctx, 1234, 7, privateKey))
+	assert.Nil(t, currentAppAuth())
+	require.NoError(t, UseAppAuth(ctx, 1234, 42, privateKey))
+	assert.Equal(t, "ghs_installation_1", token())
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := GitHub.Repositories.Get(ctx, "actions-go", "toolkit")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, map[string]int{"Bearer ghs_installation_1": 10}, authorizations)
+
+	<-after(56 * time.Minute)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := GitHub.Repositories.Get(ctx, "actions-go", "toolkit")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, minted, "tokens are renewed once before they expire")
+	assert.Equal(t, map[string]int{"Bearer ghs_installation_1": 10, "Bearer ghs_installation_2": 10}, authorizations)
+	assert.Equal(t, "ghs_installation_2", token())
+}
@@ -0,0 +1,417 @@
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+var (
+	// tarEpoch and zipEpoch are the timestamps set when mtimes are zeroed, zip can't encode dates before 1980
+	tarEpoch = time.Unix(0, 0).UTC()
+	zipEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// ArchiveOption customises archives written by WriteTarGz and WriteZip
+type ArchiveOption func(*archiveOptions)
+
+type archiveOptions struct {
+	zeroModTime bool
+}
+
+// WithZeroModTime sets all modification times to a fixed date for reproducible archives
+func WithZeroModTime() ArchiveOption {
+	return func(o *archiveOptions) {
+		o.zeroModTime = true
+	}
+}
+
+func newArchiveOptions(options []ArchiveOption) archiveOptions {
+	o := archiveOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+func isSymlink(f RepositoryFile) bool {
+	return f.FileInfo != nil && f.FileInfo.Mode()&os.ModeSymlink != 0
+}
+
+func fileMode(f RepositoryFile) os.FileMode {
+	if f.FileInfo != nil {
+		return f.FileInfo.Mode()
+	}
+	return 0644
+}
+
+func fileModTime(f RepositoryFile) time.Time {
+	if f.FileInfo != nil {
+		return f.FileInfo.ModTime()
+	}
+	return time.Time{}
+}
+
+func sortedPaths(files map[string]RepositoryFile) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// stripComponents removes the first strip path elements of an archive entry
+func stripComponents(name string, strip int) (string, bool) {
+	parts := strings.SplitN(name, "/", strip+1)
+	if len(parts) <= strip || parts[strip] == "" {
+		return "", false
+	}
+	return parts[strip], true
+}
+
+// readTarResponse extracts files matching include from an archive response, tar, gzipped tar or zip
+func readTarResponse(resp *http.Response, include Matcher, strip int, options *DownloadOptions) (map[string]RepositoryFile, error) {
+	var body io.Reader = resp.Body
+	switch resp.Header.Get("Content-Type") {
+	case "application/gzip", "application/x-gzip":
+		compressed := &countingReader{r: body}
+		gz, err := gzip.NewReader(compressed)
+		if err != nil {
+			return nil, err
+		}
+		body = newBombGuard(gz, "", func() int64 { return compressed.n }, &options.sharedCounters().inflated, options)
+	case "application/zip", "application/x-zip-compressed":
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return readZip(bytes.NewReader(b), int64(len(b)), include, strip, options)
+	}
+	return readTar(body, include, strip, options)
+}
+
+// extraction gathers the files extracted from an archive, applying download options
+type extraction struct {
+	include Matcher
+	strip   int
+	options *DownloadOptions
+	files   map[string]RepositoryFile
+	errs    MultiError
+	// counters holds the number of bytes extracted and decompressed so far, in this archive or the whole download
+	counters *downloadCounters
+}
+
+func newExtraction(include Matcher, strip int, options *DownloadOptions) *extraction {
+	return &extraction{
+		include:  include,
+		strip:    strip,
+		options:  options,
+		files:    map[string]RepositoryFile{},
+		errs:     MultiError{},
+		counters: options.sharedCounters(),
+	}
+}
+
+// name returns the name of an entry in the result, ok is false when the entry must not be extracted
+func (e *extraction) name(entry string) (name string, ok bool, err error) {
+	name, ok = stripComponents(entry, e.strip)
+	if !ok {
+		if e.options.StrictMode {
+			return "", false, e.failed(entry, fmt.Errorf("it has less than %d path elements", e.strip+1))
+		}
+		core.Warningf("skipping %s, it has less than %d path elements", entry, e.strip+1)
+		return "", false, nil
+	}
+	return name, e.include(name), nil
+}
+
+// failed records a per-file error, it returns the error when extraction must stop
+func (e *extraction) failed(entry string, err error) error {
+	err = fmt.Errorf("failed to extract %s: %v", entry, err)
+	if e.options.ContinueOnError {
+		e.errs = append(e.errs, err)
+		return nil
+	}
+	return err
+}
+
+// readLimit returns the number of bytes to read at most from the next entry to check the size limits, -1 when unlimited.
+// The file limit takes precedence so that oversize files are told apart from files crossing the total limit
+func (e *extraction) readLimit() int64 {
+	if e.options.MaxFileBytes > 0 {
+		return e.options.MaxFileBytes
+	}
+	if e.options.MaxTotalBytes > 0 {
+		return e.options.MaxTotalBytes - e.counters.extracted
+	}
+	return -1
+}
+
+// checkSize returns whether an entry of size bytes must be kept, or an error when extraction must stop
+func (e *extraction) checkSize(entry string, size int64) (bool, error) {
+	if e.options.MaxFileBytes > 0 && size > e.options.MaxFileBytes {
+		if e.options.SkipOversizeFiles {
+			core.Warningf("skipping %s, it is larger than %d bytes", entry, e.options.MaxFileBytes)
+			return false, nil
+		}
+		return false, &ErrSizeLimitExceeded{Path: entry, Limit: e.options.MaxFileBytes}
+	}
+	if e.options.MaxTotalBytes > 0 && e.counters.extracted+size > e.options.MaxTotalBytes {
+		return false, &ErrSizeLimitExceeded{Path: entry, Limit: e.options.MaxTotalBytes, Total: true}
+	}
+	e.counters.extracted += size
+	return true, nil
+}
+
+// readAtMost reads r entirely, or limit+1 bytes when limit is positive so that larger content can be detected without reading it all
+func readAtMost(r io.Reader, limit int64) ([]byte, error) {
+	if limit >= 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+	return ioutil.ReadAll(r)
+}
+
+// add reads an entry, given the number of bytes it may hold, and stores it in the result.
+// It returns an error when extraction must stop
+func (e *extraction) add(entry, name string, info os.FileInfo, read func(limit int64) ([]byte, error)) error {
+	core.Debugf("Downloading %v", entry)
+	data, err := read(e.readLimit())
+	if isDecompressionBomb(err) {
+		return err
+	}
+	if err == nil {
+		keep, err := e.checkSize(entry, int64(len(data)))
+		if !keep {
+			return err
+		}
+	}
+	if err == nil && info.Mode()&os.ModeSymlink == 0 {
+		data = normalizeLineEndings(data, e.options.LineEndings)
+		if e.options.Transform != nil {
+			data, err = e.options.Transform(name, data)
+		}
+	}
+	if err != nil {
+		return e.failed(entry, err)
+	}
+	e.files[name] = RepositoryFile{
+		Path:     name,
+		FileInfo: info,
+		Data:     data,
+	}
+	return nil
+}
+
+func (e *extraction) result() (map[string]RepositoryFile, error) {
+	return e.files, e.errs.errorOrNil()
+}
+
+func readTar(r io.Reader, include Matcher, strip int, options *DownloadOptions) (map[string]RepositoryFile, error) {
+	e := newExtraction(include, strip, options)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break // End of archive
+		}
+		if err != nil {
+			// the archive can't be read any further
+			if options.ContinueOnError && !isDecompressionBomb(err) {
+				e.errs = append(e.errs, err)
+				return e.result()
+			}
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeXGlobalHeader || hdr.FileInfo().IsDir() {
+			continue
+		}
+		name, ok, err := e.name(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		err = e.add(hdr.Name, name, hdr.FileInfo(), func(limit int64) ([]byte, error) {
+			if hdr.Typeflag == tar.TypeSymlink {
+				return []byte(hdr.Linkname), nil
+			}
+			return readAtMost(tr, limit)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e.result()
+}
+
+func readZip(r io.ReaderAt, size int64, include Matcher, strip int, options *DownloadOptions) (map[string]RepositoryFile, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	e := newExtraction(include, strip, options)
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name, ok, err := e.name(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		f := f
+		if err := e.add(f.Name, name, f.FileInfo(), func(limit int64) ([]byte, error) { return readZipFile(f, limit, &e.counters.inflated, options) }); err != nil {
+			return nil, err
+		}
+	}
+	return e.result()
+}
+
+func readZipFile(f *zip.File, limit int64, inflated *int64, options *DownloadOptions) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return readAtMost(newBombGuard(rc, f.Name, func() int64 { return int64(f.CompressedSize64) }, inflated, options), limit)
+}
+
+// WriteTarGz writes files as a gzipped tarball, preserving their modes and symbolic links.
+// Entries are sorted by path so the same files always produce the same archive.
+func WriteTarGz(w io.Writer, files map[string]RepositoryFile, options ...ArchiveOption) error {
+	o := newArchiveOptions(options)
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, p := range sortedPaths(files) {
+		f := files[p]
+		hdr := &tar.Header{
+			Name:     p,
+			Mode:     int64(fileMode(f).Perm()),
+			Size:     int64(len(f.Data)),
+			ModTime:  fileModTime(f),
+			Typeflag: tar.TypeReg,
+		}
+		if isSymlink(f) {
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = string(f.Data)
+			hdr.Size = 0
+		}
+		if o.zeroModTime {
+			hdr.ModTime = tarEpoch
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write header for %s: %v", p, err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write(f.Data); err != nil {
+				return fmt.Errorf("failed to write %s: %v", p, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// WriteZip writes files as a zip archive, preserving their modes and symbolic links.
+// Entries are sorted by path so the same files always produce the same archive.
+func WriteZip(w io.Writer, files map[string]RepositoryFile, options ...ArchiveOption) error {
+	o := newArchiveOptions(options)
+	zw := zip.NewWriter(w)
+	for _, p := range sortedPaths(files) {
+		f := files[p]
+		hdr := &zip.FileHeader{
+			Name:     p,
+			Method:   zip.Deflate,
+			Modified: fileModTime(f),
+		}
+		if o.zeroModTime || hdr.Modified.IsZero() {
+			hdr.Modified = zipEpoch
+		}
+		hdr.SetMode(fileMode(f))
+		if isSymlink(f) {
+			hdr.Method = zip.Store
+		}
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return fmt.Errorf("failed to write header for %s: %v", p, err)
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return fmt.Errorf("failed to write %s: %v", p, err)
+		}
+	}
+	return zw.Close()
+}
+
+// filesFromDir reads all files of a directory, without following symbolic links
+func filesFromDir(dir string) (map[string]RepositoryFile, error) {
+	files := map[string]RepositoryFile{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		var data []byte
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			data = []byte(target)
+		} else {
+			data, err = ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+		}
+		files[rel] = RepositoryFile{
+			Path:     rel,
+			FileInfo: info,
+			Data:     data,
+		}
+		return nil
+	})
+	return files, err
+}
+
+// WriteTarGzDir writes the content of a directory as a gzipped tarball, see WriteTarGz
+func WriteTarGzDir(w io.Writer, dir string, options ...ArchiveOption) error {
+	files, err := filesFromDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %v", dir, err)
+	}
+	return WriteTarGz(w, files, options...)
+}
+
+// WriteZipDir writes the content of a directory as a zip archive, see WriteZip
+func WriteZipDir(w io.Writer, dir string, options ...ArchiveOption) error {
+	files, err := filesFromDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %v", dir, err)
+	}
+	return WriteZip(w, files, options...)
+}
@@ -0,0 +1,419 @@
+package github
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFile(name string, mode int64, typeflag byte, data string) RepositoryFile {
+	hdr := &tar.Header{Name: name, Mode: mode, Typeflag: typeflag, ModTime: time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)}
+	return RepositoryFile{Path: name, FileInfo: hdr.FileInfo(), Data: []byte(data)}
+}
+
+func testArchiveFiles() map[string]RepositoryFile {
+	return map[string]RepositoryFile{
+		"README.md":      testFile("README.md", 0644, tar.TypeReg, "# hello"),
+		"bin/run.sh":     testFile("bin/run.sh", 0755, tar.TypeReg, "#!/bin/sh\necho hello\n"),
+		"bin/latest":     testFile("bin/latest", 0777, tar.TypeSymlink, "run.sh"),
+		"docs/empty.txt": testFile("docs/empty.txt", 0600, tar.TypeReg, ""),
+	}
+}
+
+func readArchive(t *testing.T, contentType string, b []byte) map[string]RepositoryFile {
+	resp := &http.Response{
+		Header: http.Header{"Content-Type": []string{contentType}},
+		Body:   ioutil.NopCloser(bytes.NewReader(b)),
+	}
+	files, err := readTarResponse(resp, func(string) bool { return true }, 0, &DownloadOptions{})
+	require.NoError(t, err)
+	return files
+}
+
+func assertSameFiles(t *testing.T, expected, actual map[string]RepositoryFile) {
+	assert.Len(t, actual, len(expected))
+	for name, e := range expected {
+		a, ok := actual[name]
+		if assert.True(t, ok, "missing file %s", name) {
+			assert.Equal(t, e.Path, a.Path)
+			assert.Equal(t, string(e.Data), string(a.Data), name)
+			assert.Equal(t, e.FileInfo.Mode(), a.FileInfo.Mode(), name)
+		}
+	}
+}
+
+func TestWriteTarGz(t *testing.T) {
+	files := testArchiveFiles()
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGz(b, files))
+	read := readArchive(t, "application/gzip", b.Bytes())
+	assertSameFiles(t, files, read)
+	assert.True(t, read["README.md"].FileInfo.ModTime().Equal(files["README.md"].FileInfo.ModTime()))
+
+	t.Run("with zeroed mod times, archives are reproducible", func(t *testing.T) {
+		first, second := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+		require.NoError(t, WriteTarGz(first, files, WithZeroModTime()))
+		changed := testArchiveFiles()
+		changed["README.md"] = RepositoryFile{Path: "README.md", FileInfo: (&tar.Header{Mode: 0644, ModTime: time.Now()}).FileInfo(), Data: []byte("# hello")}
+		require.NoError(t, WriteTarGz(second, changed, WithZeroModTime()))
+		assert.Equal(t, first.Bytes(), second.Bytes())
+		assert.True(t, readArchive(t, "application/gzip", first.Bytes())["README.md"].FileInfo.ModTime().Equal(tarEpoch))
+	})
+}
+
+func TestWriteZip(t *testing.T) {
+	files := testArchiveFiles()
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteZip(b, files))
+	assertSameFiles(t, files, readArchive(t, "application/zip", b.Bytes()))
+
+	t.Run("with zeroed mod times, archives are reproducible", func(t *testing.T) {
+		first, second := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+		require.NoError(t, WriteZip(first, files, WithZeroModTime()))
+		require.NoError(t, WriteZip(second, testArchiveFiles(), WithZeroModTime()))
+		assert.Equal(t, first.Bytes(), second.Bytes())
+	})
+}
+
+func TestWriteDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "run.sh"), []byte("echo hello"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# hello"), 0644))
+	require.NoError(t, os.Symlink("run.sh", filepath.Join(dir, "bin", "latest")))
+
+	expected, err := filesFromDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, expected, 3)
+	assert.Equal(t, "run.sh", string(expected["bin/latest"].Data))
+
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGzDir(b, dir))
+	assertSameFiles(t, expected, readArchive(t, "application/gzip", b.Bytes()))
+
+	b = bytes.NewBuffer(nil)
+	require.NoError(t, WriteZipDir(b, dir))
+	assertSameFiles(t, expected, readArchive(t, "application/zip", b.Bytes()))
+
+	assert.Error(t, WriteZipDir(bytes.NewBuffer(nil), filepath.Join(dir, "does-not-exist")))
+}
+
+func TestReadTarStrip(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGz(b, map[string]RepositoryFile{
+		"owner-repo-sha/main.go":    {Data: []byte("package main")},
+		"owner-repo-sha/pkg/lib.go": {Data: []byte("package pkg")},
+		"top-level-file":            {Data: []byte("skipped")},
+	}))
+	resp := &http.Response{
+		Header: http.Header{"Content-Type": []string{"application/gzip"}},
+		Body:   ioutil.NopCloser(b),
+	}
+	files, err := readTarResponse(resp, MatchesOneOf("\\.go$"), 1, &DownloadOptions{})
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "package pkg", string(files["pkg/lib.go"].Data))
+}
+
+func TestReadTarPAX(t *testing.T) {
+	// GitHub tarballs start with a global header holding the commit, entries with long or non ASCII names are PAX ones
+	long := "owner-repo-sha/" + strings.Repeat("nested/", 20) + "main.go"
+	b := bytes.NewBuffer(nil)
+	tw := tar.NewWriter(b)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "d74fd51"}}))
+	for name, content := range map[string]string{long: "package nested", "owner-repo-sha/héllo.go": "package hello"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Format: tar.FormatPAX}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	files, err := readTar(b, func(string) bool { return true }, 1, &DownloadOptions{})
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "only the global header is skipped")
+	assert.Equal(t, "package nested", string(files[strings.TrimPrefix(long, "owner-repo-sha/")].Data))
+	assert.Equal(t, "package hello", string(files["héllo.go"].Data))
+}
+
+func corruptedZip(t *testing.T) []byte {
+	b := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(b)
+	for _, f := range []struct{ name, content string }{
+		{"first.txt", "first file"},
+		{"corrupted.txt", "content to be corrupted"},
+		{"last.txt", "last file"},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Store})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	// same length to keep offsets valid, the checksum won't match anymore
+	return bytes.Replace(b.Bytes(), []byte("content to be corrupted"), []byte("CONTENT TO BE CORRUPTED"), 1)
+}
+
+func TestReadContinueOnError(t *testing.T) {
+	all := func(string) bool { return true }
+	data := corruptedZip(t)
+
+	t.Run("by default extraction fails fast", func(t *testing.T) {
+		files, err := readZip(bytes.NewReader(data), int64(len(data)), all, 0, &DownloadOptions{})
+		assert.Error(t, err)
+		assert.Nil(t, files)
+	})
+	t.Run("with ContinueOnError valid files are returned", func(t *testing.T) {
+		files, err := readZip(bytes.NewReader(data), int64(len(data)), all, 0, &DownloadOptions{ContinueOnError: true})
+		if assert.IsType(t, MultiError{}, err) {
+			assert.Len(t, err, 1)
+			assert.Contains(t, err.Error(), "corrupted.txt")
+		}
+		assert.Len(t, files, 2)
+		assert.Equal(t, "first file", string(files["first.txt"].Data))
+		assert.Equal(t, "last file", string(files["last.txt"].Data))
+	})
+	t.Run("a truncated tarball returns files read so far", func(t *testing.T) {
+		b := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(b)
+		for _, name := range []string{"first.txt", "truncated.txt"} {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1024}))
+			_, err := tw.Write(bytes.Repeat([]byte("a"), 1024))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		truncated := b.Bytes()[:512*4]
+
+		files, err := readTar(bytes.NewReader(truncated), all, 0, &DownloadOptions{})
+		assert.Error(t, err)
+		assert.Nil(t, files)
+
+		files, err = readTar(bytes.NewReader(truncated), all, 0, &DownloadOptions{ContinueOnError: true})
+		assert.Error(t, err)
+		assert.Len(t, files, 1)
+		assert.Contains(t, files, "first.txt")
+	})
+}
+
+func TestReadStrictMode(t *testing.T) {
+	all := func(string) bool { return true }
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGz(b, map[string]RepositoryFile{
+		"owner-repo-sha/main.go": {Data: []byte("package main")},
+		"top-level-file":         {Data: []byte("skipped")},
+	}))
+	archive := b.Bytes()
+	read := func(options *DownloadOptions) (map[string]RepositoryFile, error) {
+		return readTarResponse(&http.Response{
+			Header: http.Header{"Content-Type": []string{"application/gzip"}},
+			Body:   ioutil.NopCloser(bytes.NewReader(archive)),
+		}, all, 1, options)
+	}
+
+	files, err := read(&DownloadOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	files, err = read(&DownloadOptions{StrictMode: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "top-level-file")
+	assert.Nil(t, files)
+
+	t.Run("with ContinueOnError, skipped entries are listed along with extracted files", func(t *testing.T) {
+		files, err := read(&DownloadOptions{StrictMode: true, ContinueOnError: true})
+		if assert.IsType(t, MultiError{}, err) {
+			assert.Len(t, err, 1)
+			assert.Contains(t, err.Error(), "top-level-file")
+		}
+		assert.Len(t, files, 1)
+		assert.Contains(t, files, "main.go")
+	})
+
+	t.Run("invalid patterns are errors", func(t *testing.T) {
+		m, err := (&DownloadOptions{StrictMode: true}).MatchesOneOf("^main.go$", "[")
+		assert.Error(t, err)
+		assert.Nil(t, m)
+
+		m, err = (&DownloadOptions{}).MatchesOneOf("^main.go$", "[")
+		require.NoError(t, err)
+		assert.True(t, m("main.go"))
+		m, err = (*DownloadOptions)(nil).MatchesOneOf("^main.go$")
+		require.NoError(t, err)
+		assert.True(t, m("main.go"))
+	})
+}
+
+func TestReadTransform(t *testing.T) {
+	all := func(string) bool { return true }
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteZip(b, testArchiveFiles()))
+	data := b.Bytes()
+	upperReadme := func(path string, data []byte) ([]byte, error) {
+		if path != "README.md" {
+			return data, nil
+		}
+		return bytes.ToUpper(data), nil
+	}
+
+	files, err := readZip(bytes.NewReader(data), int64(len(data)), all, 0, &DownloadOptions{Transform: upperReadme})
+	assert.NoError(t, err)
+	assert.Equal(t, "# HELLO", string(files["README.md"].Data))
+	assert.Equal(t, "#!/bin/sh\necho hello\n", string(files["bin/run.sh"].Data))
+	assert.Equal(t, "run.sh", string(files["bin/latest"].Data), "symbolic links must not be transformed")
+
+	failing := func(path string, data []byte) ([]byte, error) {
+		if path == "bin/run.sh" {
+			return nil, errors.New("transform failed")
+		}
+		return data, nil
+	}
+	files, err = readZip(bytes.NewReader(data), int64(len(data)), all, 0, &DownloadOptions{Transform: failing})
+	assert.Error(t, err)
+	assert.Nil(t, files)
+
+	files, err = readZip(bytes.NewReader(data), int64(len(data)), all, 0, &DownloadOptions{Transform: failing, ContinueOnError: true})
+	assert.Error(t, err)
+	assert.Len(t, files, 3)
+	assert.NotContains(t, files, "bin/run.sh")
+}
+
+func TestReadLineEndings(t *testing.T) {
+	all := func(string) bool { return true }
+	binary := append([]byte("\x89PNG\r\n\x1a\n\x00\x00"), []byte("\r\nlines\n")...)
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGz(b, map[string]RepositoryFile{
+		"mixed.txt": {Data: []byte("unix\nwindows\r\nlast")},
+		"logo.png":  {Data: binary},
+	}))
+	archive := b.Bytes()
+	read := func(options *DownloadOptions) map[string]RepositoryFile {
+		files, err := readTarResponse(&http.Response{
+			Header: http.Header{"Content-Type": []string{"application/gzip"}},
+			Body:   ioutil.NopCloser(bytes.NewReader(archive)),
+		}, all, 0, options)
+		require.NoError(t, err)
+		return files
+	}
+
+	files := read(&DownloadOptions{})
+	assert.Equal(t, "unix\nwindows\r\nlast", string(files["mixed.txt"].Data))
+
+	files = read(&DownloadOptions{LineEndings: LineEndingsLF})
+	assert.Equal(t, "unix\nwindows\nlast", string(files["mixed.txt"].Data))
+	assert.Equal(t, binary, files["logo.png"].Data)
+
+	files = read(&DownloadOptions{LineEndings: LineEndingsCRLF, Transform: func(path string, data []byte) ([]byte, error) {
+		if path == "mixed.txt" {
+			assert.Equal(t, "unix\r\nwindows\r\nlast", string(data), "line endings are converted before transforms")
+		}
+		return data, nil
+	}})
+	assert.Equal(t, "unix\r\nwindows\r\nlast", string(files["mixed.txt"].Data))
+	assert.Equal(t, binary, files["logo.png"].Data)
+}
+
+func TestReadSizeLimits(t *testing.T) {
+	all := func(string) bool { return true }
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGz(b, map[string]RepositoryFile{
+		"a.txt":     {Data: bytes.Repeat([]byte("a"), 100)},
+		"b.txt":     {Data: bytes.Repeat([]byte("b"), 100)},
+		"large.bin": {Data: bytes.Repeat([]byte("l"), 1000)},
+		"z.txt":     {Data: bytes.Repeat([]byte("z"), 100)},
+	}))
+	archive := b.Bytes()
+	read := func(options *DownloadOptions) (map[string]RepositoryFile, error) {
+		return readTarResponse(&http.Response{
+			Header: http.Header{"Content-Type": []string{"application/gzip"}},
+			Body:   ioutil.NopCloser(bytes.NewReader(archive)),
+		}, all, 0, options)
+	}
+
+	files, err := read(&DownloadOptions{MaxTotalBytes: 1300})
+	assert.NoError(t, err)
+	assert.Len(t, files, 4)
+
+	t.Run("extraction aborts once the total limit is crossed", func(t *testing.T) {
+		files, err := read(&DownloadOptions{MaxTotalBytes: 1250, ContinueOnError: true})
+		if assert.IsType(t, &ErrSizeLimitExceeded{}, err) {
+			assert.Equal(t, &ErrSizeLimitExceeded{Path: "z.txt", Limit: 1250, Total: true}, err)
+		}
+		assert.Nil(t, files)
+	})
+	t.Run("oversize files abort the extraction", func(t *testing.T) {
+		files, err := read(&DownloadOptions{MaxFileBytes: 500})
+		assert.Equal(t, &ErrSizeLimitExceeded{Path: "large.bin", Limit: 500}, err)
+		assert.Nil(t, files)
+	})
+	t.Run("oversize files can be skipped", func(t *testing.T) {
+		files, err := read(&DownloadOptions{MaxFileBytes: 500, SkipOversizeFiles: true, MaxTotalBytes: 300})
+		assert.NoError(t, err)
+		assert.Len(t, files, 3)
+		assert.NotContains(t, files, "large.bin")
+		assert.Equal(t, 100, len(files["z.txt"].Data))
+	})
+	t.Run("zip archives are limited too", func(t *testing.T) {
+		b := bytes.NewBuffer(nil)
+		require.NoError(t, WriteZip(b, map[string]RepositoryFile{"large.bin": {Data: bytes.Repeat([]byte("l"), 1000)}}))
+		_, err := readZip(bytes.NewReader(b.Bytes()), int64(b.Len()), all, 0, &DownloadOptions{MaxFileBytes: 999})
+		assert.IsType(t, &ErrSizeLimitExceeded{}, err)
+	})
+}
+
+func TestReadDecompressionBomb(t *testing.T) {
+	all := func(string) bool { return true }
+	// zeros deflate about a thousand times
+	zeros := bytes.Repeat([]byte{0}, 4<<20)
+
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, WriteZip(b, map[string]RepositoryFile{"bomb.bin": {Data: zeros}, "README.md": {Data: []byte("# hello")}}))
+	zipped := b.Bytes()
+	assert.True(t, len(zipped) < 10<<10)
+	b = bytes.NewBuffer(nil)
+	require.NoError(t, WriteTarGz(b, map[string]RepositoryFile{"bomb.bin": {Data: zeros}, "README.md": {Data: []byte("# hello")}}))
+	tarball := b.Bytes()
+	readTarball := func(include Matcher, options *DownloadOptions) (map[string]RepositoryFile, error) {
+		return readTarResponse(&http.Response{
+			Header: http.Header{"Content-Type": []string{"application/gzip"}},
+			Body:   ioutil.NopCloser(bytes.NewReader(tarball)),
+		}, include, 0, options)
+	}
+
+	files, err := readZip(bytes.NewReader(zipped), int64(len(zipped)), all, 0, &DownloadOptions{MaxDecompressionRatio: 2000})
+	assert.NoError(t, err, "content inflating less than the ratio is accepted")
+	assert.Len(t, files, 2)
+
+	for name, options := range map[string]*DownloadOptions{
+		"ratio":              {MaxDecompressionRatio: 100, ContinueOnError: true},
+		"uncompressed bytes": {MaxUncompressedBytes: 1 << 20, ContinueOnError: true},
+	} {
+		t.Run("zip archives inflating beyond the "+name+" limit are rejected", func(t *testing.T) {
+			files, err := readZip(bytes.NewReader(zipped), int64(len(zipped)), all, 0, options)
+			if assert.IsType(t, &ErrDecompressionBomb{}, err) {
+				assert.Equal(t, "bomb.bin", err.(*ErrDecompressionBomb).Path)
+			}
+			assert.Nil(t, files)
+		})
+		t.Run("tarballs inflating beyond the "+name+" limit are rejected", func(t *testing.T) {
+			files, err := readTarball(all, options)
+			assert.IsType(t, &ErrDecompressionBomb{}, err)
+			assert.Nil(t, files)
+
+			files, err = readTarball(MatchesOneOf("README"), options)
+			assert.IsType(t, &ErrDecompressionBomb{}, err, "entries that are not extracted are inflated too")
+			assert.Nil(t, files)
+		})
+	}
+}
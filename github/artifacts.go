@@ -0,0 +1,267 @@
+package github
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/google/go-github/v32/github"
+)
+
+// TriggeringRunID returns the identifier of the workflow run that triggered a workflow_run event.
+// ok is false when the workflow was not triggered by another run
+func TriggeringRunID() (int64, bool) {
+	id := Context.Payload.WorkflowRun.GetID()
+	return id, id != 0
+}
+
+// findArtifact returns the artifact named name uploaded by a workflow run
+func findArtifact(ctx context.Context, runID int64, name string) (*github.Artifact, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		artifacts, resp, err := GitHub.Actions.ListWorkflowRunArtifacts(ctx, Context.Repo.Owner, Context.Repo.Repo, runID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts of run %d: %v", runID, err)
+		}
+		for _, a := range artifacts.Artifacts {
+			if a.GetName() == name {
+				return a, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, fmt.Errorf("artifact %s not found in run %d", name, runID)
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// openArtifact returns the content of the zip archive of an artifact, it must be closed by callers
+func openArtifact(ctx context.Context, artifact *github.Artifact) (io.ReadCloser, error) {
+	u, _, err := GitHub.Actions.DownloadArtifact(ctx, Context.Repo.Owner, Context.Repo.Repo, artifact.GetID(), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download URL of artifact %s: %v", artifact.GetName(), err)
+	}
+	core.Debugf("Downloading artifact %s", artifact.GetName())
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the download URL is signed and must not receive the token
+	resp, err := baseHTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download artifact %s: %v", artifact.GetName(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download artifact %s: unexpected code %d", artifact.GetName(), resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// downloadArtifact downloads the zip archive of an artifact
+func downloadArtifact(ctx context.Context, artifact *github.Artifact) ([]byte, error) {
+	body, err := openArtifact(ctx, artifact)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// DownloadArtifactFromRun downloads and extracts the artifact named name uploaded by another run of the repository workflows,
+// for example the run returned by TriggeringRunID
+func DownloadArtifactFromRun(ctx context.Context, runID int64, name string) (map[string]RepositoryFile, error) {
+	artifact, err := findArtifact(ctx, runID, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := downloadArtifact(ctx, artifact)
+	if err != nil {
+		return nil, err
+	}
+	return readZip(bytes.NewReader(b), int64(len(b)), func(string) bool { return true }, 0, &DownloadOptions{})
+}
+
+// DownloadArtifact downloads and extracts the artifact named name uploaded by the current workflow run
+func DownloadArtifact(ctx context.Context, name string) (map[string]RepositoryFile, error) {
+	return DownloadArtifactFromRun(ctx, RunID(), name)
+}
+
+// ArtifactExpectationOption customises the checks of DownloadArtifactExpecting
+type ArtifactExpectationOption func(*artifactExpectation)
+
+type artifactExpectation struct {
+	rejectExtra bool
+}
+
+// WithoutExtraFiles makes DownloadArtifactExpecting fail when the artifact holds files that are not expected
+func WithoutExtraFiles() ArtifactExpectationOption {
+	return func(o *artifactExpectation) {
+		o.rejectExtra = true
+	}
+}
+
+// DownloadArtifactExpecting downloads and extracts the artifact named name uploaded by the current workflow run, see
+// DownloadArtifact, and checks it holds every path of expected. An ErrMissingArtifactFiles is returned otherwise,
+// for a missing file usually reveals a failure of the job uploading the artifact
+func DownloadArtifactExpecting(ctx context.Context, name string, expected []string, options ...ArtifactExpectationOption) (map[string]RepositoryFile, error) {
+	o := artifactExpectation{}
+	for _, option := range options {
+		option(&o)
+	}
+	files, err := DownloadArtifact(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	e := &ErrMissingArtifactFiles{Artifact: name}
+	wanted := map[string]bool{}
+	for _, p := range expected {
+		wanted[p] = true
+		if _, ok := files[p]; !ok {
+			e.Missing = append(e.Missing, p)
+		}
+	}
+	if o.rejectExtra {
+		for p := range files {
+			if !wanted[p] {
+				e.Unexpected = append(e.Unexpected, p)
+			}
+		}
+		sort.Strings(e.Unexpected)
+	}
+	if len(e.Missing) > 0 || len(e.Unexpected) > 0 {
+		return nil, e
+	}
+	return files, nil
+}
+
+// StreamArtifact writes the zip archive of the artifact named name uploaded by the current workflow run to w, without extracting it
+func StreamArtifact(ctx context.Context, name string, w io.Writer) error {
+	artifact, err := findArtifact(ctx, RunID(), name)
+	if err != nil {
+		return err
+	}
+	body, err := openArtifact(ctx, artifact)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to download artifact %s: %v", name, err)
+	}
+	return nil
+}
+
+// artifactEntry reads an entry of an artifact spooled to a temporary file, removed on Close
+type artifactEntry struct {
+	io.ReadCloser
+	spool *os.File
+}
+
+func (e *artifactEntry) Close() error {
+	err := e.ReadCloser.Close()
+	e.spool.Close()
+	os.Remove(e.spool.Name())
+	return err
+}
+
+// OpenArtifactEntry returns a reader of the file at path in the artifact named name uploaded by the current workflow run,
+// for example to hash a large build output without holding it in memory. It must be closed by callers.
+// As zip archives can't be read sequentially, the archive is spooled to a temporary file, removed on Close.
+// The MaxFileBytes, MaxUncompressedBytes and MaxDecompressionRatio options, when set, make reads fail once the entry exceeds them.
+// An ErrNotFound is returned when the artifact does not contain path
+func OpenArtifactEntry(ctx context.Context, name, path string, options *DownloadOptions) (io.ReadCloser, error) {
+	if options == nil {
+		options = &DownloadOptions{}
+	}
+	spool, err := ioutil.TempFile("", "artifact-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	entry, err := openSpooledEntry(ctx, spool, name, path, options)
+	if err != nil {
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, err
+	}
+	return &artifactEntry{ReadCloser: entry, spool: spool}, nil
+}
+
+// limitedEntry reads an entry of a zip archive through the readers checking the download options
+type limitedEntry struct {
+	io.Reader
+	io.Closer
+}
+
+// fileLimitReader fails reads once more than limit bytes have been read, zip headers may lie about the size of entries
+type fileLimitReader struct {
+	r     io.Reader
+	path  string
+	limit int64
+	read  int64
+}
+
+func (l *fileLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, &ErrSizeLimitExceeded{Path: l.path, Limit: l.limit}
+	}
+	return n, err
+}
+
+func openSpooledEntry(ctx context.Context, spool *os.File, name, path string, options *DownloadOptions) (io.ReadCloser, error) {
+	if err := StreamArtifact(ctx, name, spool); err != nil {
+		return nil, err
+	}
+	info, err := spool.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(spool, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact %s: %v", name, err)
+	}
+	for _, f := range zr.File {
+		if f.Name != path {
+			continue
+		}
+		if options.MaxFileBytes > 0 && f.UncompressedSize64 > uint64(options.MaxFileBytes) {
+			return nil, &ErrSizeLimitExceeded{Path: path, Limit: options.MaxFileBytes}
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s from artifact %s: %v", path, name, err)
+		}
+		var inflated int64
+		r := newBombGuard(rc, path, func() int64 { return int64(f.CompressedSize64) }, &inflated, options)
+		if options.MaxFileBytes > 0 {
+			r = &fileLimitReader{r: io.LimitReader(r, options.MaxFileBytes+1), path: path, limit: options.MaxFileBytes}
+		}
+		return limitedEntry{Reader: r, Closer: rc}, nil
+	}
+	return nil, &ErrNotFound{Resource: fmt.Sprintf("%s in artifact %s", path, name), Err: fmt.Errorf("no such file")}
+}
+
+// StreamArtifactFile writes the content of the file at path in the artifact named name uploaded by the current workflow run to w.
+// As zip archives can't be read sequentially, the archive is spooled to a temporary file rather than held in memory.
+// Size limits of options apply as for OpenArtifactEntry.
+// An ErrNotFound is returned when the artifact does not contain path
+func StreamArtifactFile(ctx context.Context, name, path string, w io.Writer, options *DownloadOptions) error {
+	rc, err := OpenArtifactEntry(ctx, name, path, options)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if _, err := io.Copy(w, rc); err != nil {
+		return fmt.Errorf("failed to extract %s from artifact %s: %v", path, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// artifactsMux serves the artifacts of run 30433642, the archive of the build artifact is served by the same server
+func artifactsMux(t *testing.T, files map[string]RepositoryFile) *http.ServeMux {
+	archive := bytes.NewBuffer(nil)
+	require.NoError(t, WriteZip(archive, files))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/30433642/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"total_count": 2, "artifacts": [{"id": 12, "name": "build"}]}`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		fmt.Fprint(w, `{"total_count": 2, "artifacts": [{"id": 11, "name": "coverage"}]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/artifacts/12/zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", fmt.Sprintf("http://%s/blobs/build.zip?sig=signed", r.Host))
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/blobs/build.zip", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "signed", r.URL.Query().Get("sig"))
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(archive.Bytes())
+	})
+	return mux
+}
+
+func TestDownloadArtifactFromRun(t *testing.T) {
+	defer setEnv(map[string]string{"GITHUB_EVENT_NAME": "workflow_run", "GITHUB_EVENT_PATH": "workflow_run_event.json"})()
+	defer mockContext(ParseActionEnv())()
+	defer mockGitHub(artifactsMux(t, map[string]RepositoryFile{
+		"bin/app": {Data: []byte("binary")},
+	}))()
+
+	runID, ok := TriggeringRunID()
+	require.True(t, ok)
+	assert.Equal(t, int64(30433642), runID)
+
+	files, err := DownloadArtifactFromRun(context.Background(), runID, "build")
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "binary", string(files["bin/app"].Data))
+
+	_, err = DownloadArtifactFromRun(context.Background(), runID, "missing")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "artifact missing not found")
+	}
+
+	t.Run("other events have no triggering run", func(t *testing.T) {
+		Context = ActionContext{}
+		_, ok := TriggeringRunID()
+		assert.False(t, ok)
+	})
+}
+
+func TestStreamArtifact(t *testing.T) {
+	files := map[string]RepositoryFile{
+		"bin/app":  {Data: []byte("binary")},
+		"README":   {Data: []byte("read me")},
+		"doc/a.md": {Data: []byte("# A")},
+	}
+	defer mockEnv(map[string]string{"GITHUB_RUN_ID": "30433642"})()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	defer mockGitHub(artifactsMux(t, files))()
+	archive := bytes.NewBuffer(nil)
+	require.NoError(t, WriteZip(archive, files))
+
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, StreamArtifact(context.Background(), "build", b))
+	assert.Equal(t, archive.Bytes(), b.Bytes())
+
+	b.Reset()
+	require.NoError(t, StreamArtifactFile(context.Background(), "build", "bin/app", b, nil))
+	assert.Equal(t, "binary", b.String())
+
+	err := StreamArtifactFile(context.Background(), "build", "bin/missing", b, nil)
+	assert.IsType(t, &ErrNotFound{}, err)
+	assert.Error(t, StreamArtifact(context.Background(), "missing", b))
+
+	err = StreamArtifactFile(context.Background(), "build", "bin/app", b, &DownloadOptions{MaxFileBytes: 3})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "bin/app exceeds the file limit of 3 bytes")
+	}
+}
+
+func TestDownloadArtifactExpecting(t *testing.T) {
+	defer mockEnv(map[string]string{"GITHUB_RUN_ID": "30433642"})()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	defer mockGitHub(artifactsMux(t, map[string]RepositoryFile{
+		"bin/app":      {Data: []byte("binary")},
+		"coverage.out": {Data: []byte("mode: set")},
+		"README":       {Data: []byte("read me")},
+	}))()
+	ctx := context.Background()
+
+	files, err := DownloadArtifactExpecting(ctx, "build", []string{"bin/app", "coverage.out"})
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	_, err = DownloadArtifactExpecting(ctx, "build", []string{"bin/app", "bin/app.sha256", "report.xml"})
+	e, ok := err.(*ErrMissingArtifactFiles)
+	require.True(t, ok, err)
+	assert.Equal(t, []string{"bin/app.sha256", "report.xml"}, e.Missing)
+	assert.Empty(t, e.Unexpected)
+	assert.Equal(t, "artifact build is missing bin/app.sha256, report.xml", err.Error())
+
+	_, err = DownloadArtifactExpecting(ctx, "build", []string{"bin/app"}, WithoutExtraFiles())
+	e, ok = err.(*ErrMissingArtifactFiles)
+	require.True(t, ok, err)
+	assert.Empty(t, e.Missing)
+	assert.Equal(t, []string{"README", "coverage.out"}, e.Unexpected)
+
+	files, err = DownloadArtifactExpecting(ctx, "build", []string{"bin/app", "coverage.out", "README"}, WithoutExtraFiles())
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+}
+
+func TestOpenArtifactEntry(t *testing.T) {
+	output := make([]byte, 5<<20)
+	_, err := rand.Read(output)
+	require.NoError(t, err)
+	defer mockEnv(map[string]string{"GITHUB_RUN_ID": "30433642"})()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	defer mockGitHub(artifactsMux(t, map[string]RepositoryFile{
+		"dist/output.bin": {Data: output},
+		"dist/zeros.bin":  {Data: make([]byte, 5<<20)},
+		"README":          {Data: []byte("read me")},
+	}))()
+
+	rc, err := OpenArtifactEntry(context.Background(), "build", "dist/output.bin", nil)
+	require.NoError(t, err)
+	h := sha256.New()
+	_, err = io.Copy(h, rc)
+	require.NoError(t, err)
+	expected := sha256.Sum256(output)
+	assert.Equal(t, expected[:], h.Sum(nil))
+
+	spool := rc.(*artifactEntry).spool.Name()
+	assert.FileExists(t, spool)
+	assert.NoError(t, rc.Close())
+	_, err = os.Stat(spool)
+	assert.True(t, os.IsNotExist(err), "the spooled archive is removed on close")
+
+	_, err = OpenArtifactEntry(context.Background(), "build", "dist/missing.bin", nil)
+	assert.IsType(t, &ErrNotFound{}, err)
+
+	t.Run("download options limit the entry", func(t *testing.T) {
+		_, err := OpenArtifactEntry(context.Background(), "build", "dist/output.bin", &DownloadOptions{MaxFileBytes: 1 << 20})
+		assert.Equal(t, &ErrSizeLimitExceeded{Path: "dist/output.bin", Limit: 1 << 20}, err)
+
+		for _, options := range []*DownloadOptions{{MaxUncompressedBytes: 1 << 20}, {MaxDecompressionRatio: 10}} {
+			rc, err := OpenArtifactEntry(context.Background(), "build", "dist/zeros.bin", options)
+			require.NoError(t, err)
+			_, err = io.Copy(ioutil.Discard, rc)
+			assert.IsType(t, &ErrDecompressionBomb{}, err)
+			assert.NoError(t, rc.Close())
+		}
+	})
+}
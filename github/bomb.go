@@ -0,0 +1,62 @@
+package github
+
+import (
+	"io"
+)
+
+// bombRatioMinBytes is the uncompressed size from which the decompression ratio is checked, small highly compressible files are common
+const bombRatioMinBytes = 1 << 20
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// bombGuard fails reads of decompressed content once it exceeds the MaxUncompressedBytes or MaxDecompressionRatio download options
+type bombGuard struct {
+	r    io.Reader
+	path string
+	// compressed returns the number of compressed bytes the content read so far has been inflated from
+	compressed func() int64
+	// inflated counts the bytes decompressed, it may be shared by the entries of an archive
+	inflated *int64
+	// read counts the bytes read from r
+	read    int64
+	options *DownloadOptions
+}
+
+func newBombGuard(r io.Reader, path string, compressed func() int64, inflated *int64, options *DownloadOptions) io.Reader {
+	if options.MaxUncompressedBytes <= 0 && options.MaxDecompressionRatio <= 0 {
+		return r
+	}
+	return &bombGuard{r: r, path: path, compressed: compressed, inflated: inflated, options: options}
+}
+
+func (g *bombGuard) Read(p []byte) (int, error) {
+	n, err := g.r.Read(p)
+	g.read += int64(n)
+	*g.inflated += int64(n)
+	if g.options.MaxUncompressedBytes > 0 && *g.inflated > g.options.MaxUncompressedBytes {
+		return n, &ErrDecompressionBomb{Path: g.path, Compressed: g.compressed(), Uncompressed: *g.inflated}
+	}
+	if g.options.MaxDecompressionRatio > 0 && g.read > bombRatioMinBytes {
+		compressed := g.compressed()
+		if compressed <= 0 || float64(g.read)/float64(compressed) > g.options.MaxDecompressionRatio {
+			return n, &ErrDecompressionBomb{Path: g.path, Compressed: compressed, Uncompressed: g.read}
+		}
+	}
+	return n, err
+}
+
+// isDecompressionBomb reports whether err is an ErrDecompressionBomb, extraction must stop regardless of ContinueOnError
+func isDecompressionBomb(err error) bool {
+	_, ok := err.(*ErrDecompressionBomb)
+	return ok
+}
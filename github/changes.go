@@ -0,0 +1,222 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	// compareFilesLimit is the number of files listed when comparing commits, larger comparisons are truncated
+	compareFilesLimit = 300
+	// commitFilesLimit is the number of files listed, across all pages, for a single commit
+	commitFilesLimit = 3000
+)
+
+func isZeroSHA(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// compareFiles lists the files changed between base and head, an ErrFilesTruncated is returned when the list is truncated
+func compareFiles(ctx context.Context, base, head string) ([]*github.CommitFile, *github.Response, error) {
+	comparison, resp, err := GitHub.Repositories.CompareCommits(ctx, Context.Repo.Owner, Context.Repo.Repo, base, head)
+	if err != nil {
+		return nil, resp, err
+	}
+	if len(comparison.Files) >= compareFilesLimit {
+		return nil, resp, &ErrFilesTruncated{Comparison: base + "..." + head, Limit: compareFilesLimit}
+	}
+	return comparison.Files, resp, nil
+}
+
+// commitFiles lists the files changed by the commit sha, following the pages the API splits them in.
+// An ErrFilesTruncated is returned when the list is truncated
+func commitFiles(ctx context.Context, sha string) ([]*github.CommitFile, error) {
+	files := []*github.CommitFile{}
+	for page := 1; page != 0; {
+		u := fmt.Sprintf("repos/%v/%v/commits/%v?page=%d&per_page=100", Context.Repo.Owner, Context.Repo.Repo, sha, page)
+		req, err := GitHub.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		commit := &github.RepositoryCommit{}
+		resp, err := GitHub.Do(ctx, req, commit)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, commit.Files...)
+		page = resp.NextPage
+	}
+	if len(files) >= commitFilesLimit {
+		return nil, &ErrFilesTruncated{Comparison: "commit " + sha, Limit: commitFilesLimit}
+	}
+	return files, nil
+}
+
+// changedCommitFiles lists the files changed by the pull request or the push that triggered the workflow
+func changedCommitFiles(ctx context.Context) ([]*github.CommitFile, error) {
+	owner, repo := Context.Repo.Owner, Context.Repo.Repo
+	if Context.Payload.PullRequest != nil {
+		number := Context.Payload.PullRequest.GetNumber()
+		files := []*github.CommitFile{}
+		opts := &github.ListOptions{PerPage: 100}
+		for {
+			page, resp, err := GitHub.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list files of pull request %d: %v", number, err)
+			}
+			files = append(files, page...)
+			if resp.NextPage == 0 {
+				return files, nil
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	if Context.Payload.PushEvent != nil && Context.Payload.GetAfter() != "" {
+		before, after := Context.Payload.GetBefore(), Context.Payload.GetAfter()
+		// A new branch has no previous commit to compare with, only consider the pushed head
+		if isZeroSHA(before) {
+			files, err := commitFiles(ctx, after)
+			if _, ok := err.(*ErrFilesTruncated); err != nil && !ok {
+				return nil, fmt.Errorf("failed to get commit %s: %v", after, err)
+			}
+			return files, err
+		}
+		files, _, err := compareFiles(ctx, before, after)
+		if _, ok := err.(*ErrFilesTruncated); err != nil && !ok {
+			return nil, fmt.Errorf("failed to compare %s...%s: %v", before, after, err)
+		}
+		return files, err
+	}
+	return nil, fmt.Errorf("unable to list changed files for %s events", Context.EventName)
+}
+
+// ChangedFiles returns the path of files changed by the pull request or the push that triggered the workflow.
+// When a push changes more files than the API lists, an ErrFilesTruncated is returned
+func ChangedFiles(ctx context.Context) ([]string, error) {
+	files, err := changedCommitFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.GetFilename())
+	}
+	return paths, nil
+}
+
+// ChangedFilesMatching returns the path of changed files, see ChangedFiles, that the include matcher accepts
+func ChangedFilesMatching(ctx context.Context, include Matcher) ([]string, error) {
+	files, err := ChangedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching := []string{}
+	for _, f := range files {
+		if include(f) {
+			matching = append(matching, f)
+		}
+	}
+	return matching, nil
+}
+
+// blobFileInfo describes a file downloaded from its git blob, outside of an archive
+type blobFileInfo struct {
+	name string
+	size int64
+}
+
+func (i blobFileInfo) Name() string       { return path.Base(i.name) }
+func (i blobFileInfo) Size() int64        { return i.size }
+func (i blobFileInfo) Mode() os.FileMode  { return 0644 }
+func (i blobFileInfo) ModTime() time.Time { return time.Time{} }
+func (i blobFileInfo) IsDir() bool        { return false }
+func (i blobFileInfo) Sys() interface{}   { return nil }
+
+// DownloadChangedFiles downloads the content of the files changed by the pull request or the push that triggered the
+// workflow, see ChangedFiles, that the include matcher accepts, as of the head commit. Removed files are skipped and renamed files are
+// downloaded at their new path. Only the changed files are downloaded, rather than the whole repository
+func DownloadChangedFiles(ctx context.Context, include Matcher) (map[string]RepositoryFile, error) {
+	changed, err := changedCommitFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]RepositoryFile{}
+	for _, f := range changed {
+		name := f.GetFilename()
+		if f.GetStatus() == "removed" || !include(name) {
+			continue
+		}
+		data, _, err := GitHub.Git.GetBlobRaw(ctx, Context.Repo.Owner, Context.Repo.Repo, f.GetSHA())
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %v", name, err)
+		}
+		files[name] = RepositoryFile{
+			Path:     name,
+			FileInfo: blobFileInfo{name: name, size: int64(len(data))},
+			Data:     data,
+		}
+	}
+	return files, nil
+}
+
+// PathChangedBetween returns whether any file changed between the base and head commits of the repository running the workflow matches include,
+// along with the matching files. When base is not reachable, for example after a force push, an error is returned and callers should consider everything changed.
+// So should they when more files changed than the API lists, an ErrFilesTruncated is returned then
+func PathChangedBetween(ctx context.Context, base, head string, include Matcher) (bool, []string, error) {
+	files, resp, err := compareFiles(ctx, base, head)
+	if _, ok := err.(*ErrFilesTruncated); ok {
+		return false, nil, err
+	}
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil, fmt.Errorf("unable to compare %s...%s, %s may have been force pushed, run a full build instead: %v", base, head, base, err)
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to compare %s...%s: %v", base, head, err)
+	}
+	matching := []string{}
+	for _, f := range files {
+		if include(f.GetFilename()) {
+			matching = append(matching, f.GetFilename())
+		}
+	}
+	return len(matching) > 0, matching, nil
+}
+
+// ComparisonBase returns the commit changes of the workflow should be compared with: the base of pull requests and
+// merge groups, or the commit a push updated. For the first push of a branch, the merge base with the default branch is
+// returned and isFirstRun is true, for callers to decide whether to build everything instead. sha is empty when the
+// default branch itself is pushed for the first time
+func ComparisonBase(ctx context.Context) (sha string, isFirstRun bool, err error) {
+	if Context.Payload.PullRequest != nil {
+		return Context.Payload.PullRequest.GetBase().GetSHA(), false, nil
+	}
+	if base := MergeGroupBaseSHA(); base != "" {
+		return base, false, nil
+	}
+	if Context.Payload.PushEvent == nil || Context.Payload.GetAfter() == "" {
+		return "", false, fmt.Errorf("unable to find a comparison base for %s events", Context.EventName)
+	}
+	before, after := Context.Payload.GetBefore(), Context.Payload.GetAfter()
+	if !isZeroSHA(before) {
+		return before, false, nil
+	}
+	meta, err := RepositoryMetadata(ctx)
+	if err != nil {
+		return "", true, err
+	}
+	if strings.TrimPrefix(Context.Payload.GetRef(), "refs/heads/") == meta.DefaultBranch {
+		return "", true, nil
+	}
+	comparison, _, err := GitHub.Repositories.CompareCommits(ctx, Context.Repo.Owner, Context.Repo.Repo, meta.DefaultBranch, after)
+	if err != nil {
+		return "", true, fmt.Errorf("failed to compare %s...%s: %v", meta.DefaultBranch, after, err)
+	}
+	return comparison.GetMergeBaseCommit().GetSHA(), true, nil
+}
@@ -0,0 +1,216 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changedFilesJSON returns the JSON list of n changed files
+func changedFilesJSON(n int) string {
+	files := make([]string, n)
+	for i := range files {
+		files[i] = fmt.Sprintf(`{"filename": "file%d.go"}`, i)
+	}
+	return "[" + strings.Join(files, ", ") + "]"
+}
+
+func TestChangedFilesMatching(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/12/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"filename": "cache/tool.go"}, {"filename": ".github/workflows/ci.yml"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		fmt.Fprint(w, `[{"filename": "README.md"}, {"filename": "core/core.go"}]`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/abc...def", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"files": [{"filename": "go.mod"}, {"filename": "github/github.go"}]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/large...def", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"files": %s}`, changedFilesJSON(compareFilesLimit))
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/def", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"sha": "def", "files": [{"filename": "cache/module.go"}]}`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		fmt.Fprint(w, `{"sha": "def", "files": [{"filename": "module.go"}]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/large", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"sha": "large", "files": %s}`, changedFilesJSON(commitFilesLimit))
+	})
+	defer mockGitHub(mux)()
+	repo := ActionRepo{Owner: "actions-go", Repo: "toolkit"}
+	goFiles := MatchesOneOf("\\.go$")
+
+	t.Run("for pull requests, all pages are listed", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PullRequest: &github.PullRequest{Number: github.Int(12)}}})()
+		files, err := ChangedFiles(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, files, 4)
+		files, err = ChangedFilesMatching(context.Background(), goFiles)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"core/core.go", "cache/tool.go"}, files)
+	})
+	t.Run("for pushes, before and after commits are compared", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PushEvent: &github.PushEvent{Before: github.String("abc"), After: github.String("def")}}})()
+		files, err := ChangedFilesMatching(context.Background(), goFiles)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"github/github.go"}, files)
+	})
+	t.Run("for new branches, all pages of the head commit files are returned", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PushEvent: &github.PushEvent{Before: github.String("0000000000000000000000000000000000000000"), After: github.String("def")}}})()
+		files, err := ChangedFilesMatching(context.Background(), goFiles)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"module.go", "cache/module.go"}, files)
+	})
+	t.Run("pushes changing more files than the API lists are reported", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PushEvent: &github.PushEvent{Before: github.String("large"), After: github.String("def")}}})()
+		_, err := ChangedFilesMatching(context.Background(), goFiles)
+		assert.IsType(t, &ErrFilesTruncated{}, err)
+
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PushEvent: &github.PushEvent{Before: github.String("0000000000000000000000000000000000000000"), After: github.String("large")}}})()
+		_, err = DownloadChangedFiles(context.Background(), goFiles)
+		assert.IsType(t, &ErrFilesTruncated{}, err)
+	})
+	t.Run("other events are not supported", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, EventName: "schedule"})()
+		_, err := ChangedFilesMatching(context.Background(), goFiles)
+		assert.Error(t, err)
+	})
+}
+
+func TestPathChangedBetween(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/abc...def", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"files": [{"filename": "cache/tool.go"}, {"filename": "README.md"}, {"filename": "cache/README.md"}]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/forced...def", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/large...def", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"files": %s}`, changedFilesJSON(compareFilesLimit))
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	changed, files, err := PathChangedBetween(context.Background(), "abc", "def", MatchesOneOf("^cache/"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"cache/tool.go", "cache/README.md"}, files)
+
+	changed, files, err = PathChangedBetween(context.Background(), "abc", "def", MatchesOneOf("^core/"))
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, files)
+
+	t.Run("unreachable bases suggest a full build", func(t *testing.T) {
+		changed, files, err := PathChangedBetween(context.Background(), "forced", "def", MatchesOneOf("^cache/"))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "full build")
+		}
+		assert.False(t, changed)
+		assert.Nil(t, files)
+	})
+
+	t.Run("truncated comparisons are reported", func(t *testing.T) {
+		changed, files, err := PathChangedBetween(context.Background(), "large", "def", MatchesOneOf("^cache/"))
+		if assert.IsType(t, &ErrFilesTruncated{}, err) {
+			assert.Equal(t, "large...def", err.(*ErrFilesTruncated).Comparison)
+		}
+		assert.False(t, changed)
+		assert.Nil(t, files)
+	})
+}
+
+func TestDownloadChangedFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/12/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"filename": "core/core.go", "status": "modified", "sha": "blob-core"},
+			{"filename": "github/old.go", "status": "removed", "sha": "blob-old"},
+			{"filename": "github/new.go", "previous_filename": "github/renamed.go", "status": "renamed", "sha": "blob-new"},
+			{"filename": "README.md", "status": "added", "sha": "blob-readme"}
+		]`)
+	})
+	for sha, content := range map[string]string{"blob-core": "package core", "blob-new": "package github"} {
+		content := content
+		mux.HandleFunc("/repos/actions-go/toolkit/git/blobs/"+sha, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/vnd.github.v3.raw", r.Header.Get("Accept"))
+			fmt.Fprint(w, content)
+		})
+	}
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, Payload: WebhookPayload{PullRequest: &github.PullRequest{Number: github.Int(12)}}})()
+
+	files, err := DownloadChangedFiles(context.Background(), MatchesOneOf("\\.go$"))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "package core", string(files["core/core.go"].Data))
+	assert.Equal(t, "github/new.go", files["github/new.go"].Path)
+	assert.Equal(t, "package github", string(files["github/new.go"].Data))
+	assert.Equal(t, "new.go", files["github/new.go"].FileInfo.Name())
+	assert.EqualValues(t, 14, files["github/new.go"].FileInfo.Size())
+
+	_, err = DownloadChangedFiles(context.Background(), MatchesOneOf("\\.md$"))
+	assert.Error(t, err, "README.md blob is not served")
+}
+
+func TestComparisonBase(t *testing.T) {
+	defer func() { repoMetas = map[string]*RepoMeta{} }()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/main...def", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"merge_base_commit": {"sha": "merge-base"}}`)
+	})
+	defer mockGitHub(mux)()
+	repo := ActionRepo{Owner: "actions-go", Repo: "toolkit"}
+	payloadRepo := &github.Repository{Name: github.String("toolkit"), Owner: &github.User{Login: github.String("actions-go")}, DefaultBranch: github.String("main")}
+	ctx := context.Background()
+
+	t.Run("pull requests are compared with their base", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PullRequest: &github.PullRequest{Base: &github.PullRequestBranch{SHA: github.String("pr-base")}}}})()
+		sha, first, err := ComparisonBase(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "pr-base", sha)
+		assert.False(t, first)
+	})
+	t.Run("pushes are compared with the previous head", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PushEvent: &github.PushEvent{Before: github.String("abc"), After: github.String("def")}}})()
+		sha, first, err := ComparisonBase(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", sha)
+		assert.False(t, first)
+	})
+	t.Run("new branches are compared with their merge base with the default branch", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{Repository: payloadRepo, PushEvent: &github.PushEvent{
+			Ref:    github.String("refs/heads/feature"),
+			Before: github.String("0000000000000000000000000000000000000000"),
+			After:  github.String("def"),
+		}}})()
+		sha, first, err := ComparisonBase(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "merge-base", sha)
+		assert.True(t, first)
+
+		Context.Payload.PushEvent.Ref = github.String("refs/heads/main")
+		sha, first, err = ComparisonBase(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "", sha, "the initial push of the default branch has nothing to compare with")
+		assert.True(t, first)
+	})
+	t.Run("other events have no comparison base", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: repo, EventName: "schedule"})()
+		_, _, err := ComparisonBase(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,300 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	// AnnotationNotice is the level of informative annotations
+	AnnotationNotice = "notice"
+	// AnnotationWarning is the level of annotations reporting a potential problem
+	AnnotationWarning = "warning"
+	// AnnotationFailure is the level of annotations reporting an error
+	AnnotationFailure = "failure"
+
+	// checkRunAnnotationsPerRequest is the maximum number of annotations the API accepts in a single request
+	checkRunAnnotationsPerRequest = 50
+	// checkRunSummaryMaxLength is the maximum length of a check run summary
+	checkRunSummaryMaxLength = 65535
+	checkRunTruncatedNotice  = "\n\n_The summary has been truncated._"
+	// checkRunMinUpdateInterval and checkRunMaxUpdateInterval bound the spacing between buffered annotation updates
+	checkRunMinUpdateInterval = time.Second
+	checkRunMaxUpdateInterval = time.Minute
+)
+
+// Annotation reports a problem on a range of lines of a file in a check run
+type Annotation struct {
+	// Path of the file, relative to the repository root
+	Path      string
+	StartLine int
+	// EndLine defaults to StartLine
+	EndLine int
+	// StartColumn and EndColumn are only accepted by GitHub when the annotation is on a single line
+	StartColumn int
+	EndColumn   int
+	// Level is one of AnnotationNotice, AnnotationWarning or AnnotationFailure, it defaults to AnnotationWarning
+	Level      string
+	Title      string
+	Message    string
+	RawDetails string
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return github.String(s)
+}
+
+func optionalInt(i int) *int {
+	if i == 0 {
+		return nil
+	}
+	return github.Int(i)
+}
+
+func (a Annotation) checkRunAnnotation() *github.CheckRunAnnotation {
+	level := a.Level
+	if level == "" {
+		level = AnnotationWarning
+	}
+	end := a.EndLine
+	if end == 0 {
+		end = a.StartLine
+	}
+	return &github.CheckRunAnnotation{
+		Path:            github.String(a.Path),
+		StartLine:       github.Int(a.StartLine),
+		EndLine:         github.Int(end),
+		StartColumn:     optionalInt(a.StartColumn),
+		EndColumn:       optionalInt(a.EndColumn),
+		AnnotationLevel: github.String(level),
+		Message:         github.String(a.Message),
+		Title:           optionalString(a.Title),
+		RawDetails:      optionalString(a.RawDetails),
+	}
+}
+
+// truncateSummary shortens a summary to the length accepted by the API, notifying readers it has been truncated
+func truncateSummary(summary string) string {
+	if len(summary) <= checkRunSummaryMaxLength {
+		return summary
+	}
+	cut := checkRunSummaryMaxLength - len(checkRunTruncatedNotice)
+	// do not split multi-byte characters
+	for cut > 0 && !utf8.RuneStart(summary[cut]) {
+		cut--
+	}
+	return summary[:cut] + checkRunTruncatedNotice
+}
+
+// annotationKey identifies duplicated annotations
+type annotationKey struct {
+	path    string
+	line    int
+	message string
+}
+
+// CheckRunUpdater creates a check run on the commit being processed and streams annotations to it
+type CheckRunUpdater struct {
+	run     *github.CheckRun
+	summary string
+	// pending holds annotations buffered by BufferAnnotations until they fill a batch or are flushed
+	pending []Annotation
+	seen    map[annotationKey]bool
+	// interval is the spacing between buffered updates, it grows when secondary rate limits are hit
+	interval   time.Duration
+	lastUpdate time.Time
+	// limited reports whether the last update hit a secondary rate limit
+	limited bool
+}
+
+// NewCheckRunUpdater returns an updater, Start must be called before adding annotations
+func NewCheckRunUpdater() *CheckRunUpdater {
+	return &CheckRunUpdater{
+		summary:  "In progress",
+		seen:     map[annotationKey]bool{},
+		interval: checkRunMinUpdateInterval,
+	}
+}
+
+// CheckRun returns the check run managed by the updater, nil until Start succeeds
+func (u *CheckRunUpdater) CheckRun() *github.CheckRun {
+	return u.run
+}
+
+// Start creates an in progress check run named name on HeadSHA
+func (u *CheckRunUpdater) Start(ctx context.Context, name string) error {
+	var run *github.CheckRun
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		run, resp, err = GitHub.Checks.CreateCheckRun(ctx, Context.Repo.Owner, Context.Repo.Repo, github.CreateCheckRunOptions{
+			Name:    name,
+			HeadSHA: HeadSHA(),
+			Status:  github.String("in_progress"),
+		})
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create check run %s: %v", name, err)
+	}
+	u.run = run
+	return nil
+}
+
+func (u *CheckRunUpdater) update(ctx context.Context, opts github.UpdateCheckRunOptions) error {
+	if u.run == nil {
+		return fmt.Errorf("check run has not been started")
+	}
+	opts.Name = u.run.GetName()
+	return RetryRateLimited(ctx, func() (*github.Response, error) {
+		run, resp, err := GitHub.Checks.UpdateCheckRun(ctx, Context.Repo.Owner, Context.Repo.Repo, u.run.GetID(), opts)
+		if limit, ok := rateLimited(resp, err); ok && limit.Secondary {
+			u.limited = true
+		}
+		if err == nil {
+			u.run = run
+		}
+		return resp, err
+	})
+}
+
+func (u *CheckRunUpdater) sendAnnotations(ctx context.Context, anns []Annotation) error {
+	batch := make([]*github.CheckRunAnnotation, 0, len(anns))
+	for _, a := range anns {
+		batch = append(batch, a.checkRunAnnotation())
+	}
+	err := u.update(ctx, github.UpdateCheckRunOptions{
+		Output: &github.CheckRunOutput{
+			Title:       github.String(u.run.GetName()),
+			Summary:     github.String(u.summary),
+			Annotations: batch,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add annotations to check run %s: %v", u.run.GetName(), err)
+	}
+	return nil
+}
+
+// AddAnnotations appends annotations to the check run, sending them in batches of the 50 annotations the API accepts per request
+func (u *CheckRunUpdater) AddAnnotations(ctx context.Context, anns ...Annotation) error {
+	for start := 0; start < len(anns); start += checkRunAnnotationsPerRequest {
+		end := start + checkRunAnnotationsPerRequest
+		if end > len(anns) {
+			end = len(anns)
+		}
+		if err := u.sendAnnotations(ctx, anns[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BufferAnnotations queues annotations, skipping those already added with the same path, line and message.
+// Full batches are sent as they fill, spaced to avoid secondary rate limits: the spacing doubles each time one is hit.
+// Call Flush, or Complete, to send the remaining annotations
+func (u *CheckRunUpdater) BufferAnnotations(ctx context.Context, anns ...Annotation) error {
+	for _, a := range anns {
+		key := annotationKey{path: a.Path, line: a.StartLine, message: a.Message}
+		if u.seen[key] {
+			continue
+		}
+		u.seen[key] = true
+		u.pending = append(u.pending, a)
+	}
+	for len(u.pending) >= checkRunAnnotationsPerRequest {
+		if err := u.flushBatch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush sends all annotations buffered by BufferAnnotations
+func (u *CheckRunUpdater) Flush(ctx context.Context) error {
+	for len(u.pending) > 0 {
+		if err := u.flushBatch(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushBatch sends the first batch of pending annotations once the spacing since the previous buffered update elapsed
+func (u *CheckRunUpdater) flushBatch(ctx context.Context) error {
+	if u.run == nil {
+		return fmt.Errorf("check run has not been started")
+	}
+	if !u.lastUpdate.IsZero() {
+		if wait := u.lastUpdate.Add(u.interval).Sub(now()); wait > 0 {
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
+	}
+	end := checkRunAnnotationsPerRequest
+	if end > len(u.pending) {
+		end = len(u.pending)
+	}
+	u.limited = false
+	err := u.sendAnnotations(ctx, u.pending[:end])
+	u.lastUpdate = now()
+	if u.limited {
+		u.interval *= 2
+		if u.interval > checkRunMaxUpdateInterval {
+			u.interval = checkRunMaxUpdateInterval
+		}
+	}
+	if err != nil {
+		return err
+	}
+	u.pending = u.pending[end:]
+	return nil
+}
+
+// Complete marks the check run as completed with conclusion, for example success or failure, after flushing buffered annotations.
+// Summaries longer than the 65535 characters the API accepts are truncated
+func (u *CheckRunUpdater) Complete(ctx context.Context, conclusion, summary string) error {
+	if u.run == nil {
+		return fmt.Errorf("check run has not been started")
+	}
+	if err := u.Flush(ctx); err != nil {
+		return err
+	}
+	u.summary = truncateSummary(summary)
+	err := u.update(ctx, github.UpdateCheckRunOptions{
+		Status:      github.String("completed"),
+		Conclusion:  github.String(conclusion),
+		CompletedAt: &github.Timestamp{Time: now()},
+		Output: &github.CheckRunOutput{
+			Title:   github.String(u.run.GetName()),
+			Summary: github.String(u.summary),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete check run %s: %v", u.run.GetName(), err)
+	}
+	return nil
+}
+
+// CreateCheckRun creates a completed check run named name on HeadSHA with the provided annotations, sent in batches
+func CreateCheckRun(ctx context.Context, name, conclusion, summary string, annotations []Annotation) (*github.CheckRun, error) {
+	u := NewCheckRunUpdater()
+	if err := u.Start(ctx, name); err != nil {
+		return nil, err
+	}
+	if err := u.AddAnnotations(ctx, annotations...); err != nil {
+		return u.CheckRun(), err
+	}
+	if err := u.Complete(ctx, conclusion, summary); err != nil {
+		return u.CheckRun(), err
+	}
+	return u.CheckRun(), nil
+}
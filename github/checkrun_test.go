@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRunUpdater(t *testing.T) {
+	updates := []github.UpdateCheckRunOptions{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		opts := github.CreateCheckRunOptions{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		assert.Equal(t, "some-sha", opts.HeadSHA)
+		assert.Equal(t, "in_progress", opts.GetStatus())
+		fmt.Fprintf(w, `{"id": 42, "name": "%s", "status": "in_progress"}`, opts.Name)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/check-runs/42", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		opts := github.UpdateCheckRunOptions{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		updates = append(updates, opts)
+		fmt.Fprintf(w, `{"id": 42, "name": "%s", "status": "%s"}`, opts.Name, opts.GetStatus())
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{SHA: "some-sha", Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	u := NewCheckRunUpdater()
+	assert.Error(t, u.AddAnnotations(context.Background(), Annotation{Path: "main.go", StartLine: 1}), "the check run must be started first")
+	require.NoError(t, u.Start(context.Background(), "lint"))
+	assert.EqualValues(t, 42, u.CheckRun().GetID())
+
+	annotations := make([]Annotation, 130)
+	for i := range annotations {
+		annotations[i] = Annotation{Path: "main.go", StartLine: i + 1, Message: fmt.Sprintf("problem %d", i)}
+	}
+	require.NoError(t, u.AddAnnotations(context.Background(), annotations...))
+	if assert.Len(t, updates, 3) {
+		assert.Len(t, updates[0].Output.Annotations, 50)
+		assert.Len(t, updates[1].Output.Annotations, 50)
+		assert.Len(t, updates[2].Output.Annotations, 30)
+		last := updates[2].Output.Annotations[29]
+		assert.Equal(t, "problem 129", last.GetMessage())
+		assert.Equal(t, 130, last.GetEndLine(), "the end line defaults to the start line")
+		assert.Equal(t, AnnotationWarning, last.GetAnnotationLevel())
+		assert.Equal(t, "lint", updates[2].Name)
+	}
+
+	require.NoError(t, u.Complete(context.Background(), "failure", strings.Repeat("a", 70000)))
+	if assert.Len(t, updates, 4) {
+		assert.Equal(t, "completed", updates[3].GetStatus())
+		assert.Equal(t, "failure", updates[3].GetConclusion())
+		summary := updates[3].Output.GetSummary()
+		assert.Len(t, summary, checkRunSummaryMaxLength)
+		assert.True(t, strings.HasSuffix(summary, checkRunTruncatedNotice))
+	}
+}
+
+func TestCheckRunUpdaterBuffer(t *testing.T) {
+	updates := []github.UpdateCheckRunOptions{}
+	throttled := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 42, "name": "lint", "status": "in_progress"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/check-runs/42", func(w http.ResponseWriter, r *http.Request) {
+		if throttled > 0 {
+			throttled--
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit"}`)
+			return
+		}
+		opts := github.UpdateCheckRunOptions{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		updates = append(updates, opts)
+		fmt.Fprintf(w, `{"id": 42, "name": "lint", "status": "%s"}`, opts.GetStatus())
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{SHA: "some-sha", Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+
+	u := NewCheckRunUpdater()
+	require.NoError(t, u.Start(context.Background(), "lint"))
+
+	// 1000 annotations, 400 of them duplicates
+	for call := 0; call < 10; call++ {
+		annotations := make([]Annotation, 100)
+		for i := range annotations {
+			n := (call*100 + i) % 600
+			annotations[i] = Annotation{Path: "main.go", StartLine: n + 1, Message: fmt.Sprintf("problem %d", n)}
+		}
+		require.NoError(t, u.BufferAnnotations(context.Background(), annotations...))
+	}
+	assert.Len(t, updates, 12, "full batches are sent as they fill")
+	require.NoError(t, u.Flush(context.Background()))
+	assert.Len(t, updates, 12)
+
+	messages := map[string]bool{}
+	for _, update := range updates {
+		assert.Len(t, update.Output.Annotations, 50)
+		for _, a := range update.Output.Annotations {
+			assert.False(t, messages[a.GetMessage()], "annotation %s is duplicated", a.GetMessage())
+			messages[a.GetMessage()] = true
+		}
+	}
+	assert.Len(t, messages, 600)
+	assert.Equal(t, durations(11, time.Second), waits, "updates are spaced")
+
+	t.Run("spacing grows when secondary rate limits are hit", func(t *testing.T) {
+		waits = waits[:0]
+		updates = updates[:0]
+		throttled = 1
+		require.NoError(t, u.BufferAnnotations(context.Background(), Annotation{Path: "main.go", StartLine: 1, Message: "new problem"}))
+		assert.Empty(t, updates, "partial batches are buffered")
+		require.NoError(t, u.Flush(context.Background()))
+		require.NoError(t, u.BufferAnnotations(context.Background(), Annotation{Path: "main.go", StartLine: 2, Message: "other problem"}))
+		require.NoError(t, u.Complete(context.Background(), "failure", "2 problems"))
+		assert.Equal(t, []time.Duration{time.Second, 3 * time.Second, 2 * time.Second}, waits)
+		if assert.Len(t, updates, 3) {
+			assert.Equal(t, "new problem", updates[0].Output.Annotations[0].GetMessage())
+			assert.Equal(t, "other problem", updates[1].Output.Annotations[0].GetMessage())
+			assert.Equal(t, "completed", updates[2].GetStatus())
+		}
+	})
+}
+
+func durations(n int, d time.Duration) []time.Duration {
+	result := make([]time.Duration, n)
+	for i := range result {
+		result[i] = d
+	}
+	return result
+}
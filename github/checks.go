@@ -0,0 +1,85 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// WaitForCheck polls the check runs of ref until the one named checkName completes and returns it.
+// Its conclusion can be read with GetConclusion.
+// When the check does not complete within timeout, the last known check run, if any, is returned along with an error
+func WaitForCheck(ctx context.Context, ref, checkName string, timeout time.Duration) (*github.CheckRun, error) {
+	var check *github.CheckRun
+	done, err := poll(ctx, timeout, func() (bool, time.Duration, error) {
+		runs, resp, err := GitHub.Checks.ListCheckRunsForRef(ctx, Context.Repo.Owner, Context.Repo.Repo, ref, &github.ListCheckRunsOptions{CheckName: github.String(checkName)})
+		if limit, ok := rateLimited(resp, err); ok {
+			return false, limit.RetryAfter, nil
+		}
+		if err != nil {
+			return false, 0, fmt.Errorf("failed to list check runs for %s: %v", ref, err)
+		}
+		for _, run := range runs.CheckRuns {
+			if run.GetName() == checkName {
+				check = run
+				return run.GetStatus() == "completed", 0, nil
+			}
+		}
+		return false, 0, nil
+	})
+	if err != nil {
+		return check, err
+	}
+	if !done {
+		return check, fmt.Errorf("timed out after %v waiting for check %s on %s to complete", timeout, checkName, ref)
+	}
+	return check, nil
+}
+
+// CheckRuns returns all the check runs of ref, HeadSHA when ref is empty
+func CheckRuns(ctx context.Context, ref string) ([]*github.CheckRun, error) {
+	if ref == "" {
+		ref = HeadSHA()
+	}
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	all := []*github.CheckRun{}
+	for {
+		runs, resp, err := GitHub.Checks.ListCheckRunsForRef(ctx, Context.Repo.Owner, Context.Repo.Repo, ref, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list check runs for %s: %v", ref, err)
+		}
+		all = append(all, runs.CheckRuns...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// ChecksSummary counts the check runs of ref, HeadSHA when ref is empty, by outcome.
+// Successful, neutral and skipped checks are passed, checks not completed yet are pending, any other conclusion is a
+// failure and the names of failed checks are returned along
+func ChecksSummary(ctx context.Context, ref string) (passed, failed, pending int, failedNames []string, err error) {
+	runs, err := CheckRuns(ctx, ref)
+	if err != nil {
+		return 0, 0, 0, nil, err
+	}
+	failedNames = []string{}
+	for _, run := range runs {
+		if run.GetStatus() != "completed" {
+			pending++
+			continue
+		}
+		switch run.GetConclusion() {
+		case "success", "neutral", "skipped":
+			passed++
+		default:
+			failed++
+			failedNames = append(failedNames, run.GetName())
+		}
+	}
+	return passed, failed, pending, failedNames, nil
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForCheck(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/abc/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "build", r.URL.Query().Get("check_name"))
+		polls++
+		switch polls {
+		case 1:
+			fmt.Fprint(w, `{"total_count": 0, "check_runs": []}`)
+		case 2:
+			fmt.Fprint(w, `{"total_count": 1, "check_runs": [{"id": 1, "name": "build", "status": "in_progress"}]}`)
+		default:
+			fmt.Fprint(w, `{"total_count": 1, "check_runs": [{"id": 1, "name": "build", "status": "completed", "conclusion": "success"}]}`)
+		}
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	check, err := WaitForCheck(context.Background(), "abc", "build", 10*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, "success", check.GetConclusion())
+	assert.Equal(t, []time.Duration{5 * time.Second, 10 * time.Second}, waits)
+}
+
+func TestWaitForCheckTimeout(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/abc/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 1, "check_runs": [{"id": 1, "name": "build", "status": "queued"}]}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	check, err := WaitForCheck(context.Background(), "abc", "build", 2*time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, "queued", check.GetStatus())
+	assert.Equal(t, []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 45 * time.Second}, waits)
+}
+
+func TestChecksSummary(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/d74fd51/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"total_count": 6, "check_runs": [
+				{"id": 5, "name": "deploy", "status": "queued"},
+				{"id": 6, "name": "e2e", "status": "completed", "conclusion": "timed_out"}
+			]}`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		fmt.Fprint(w, `{"total_count": 6, "check_runs": [
+			{"id": 1, "name": "build", "status": "completed", "conclusion": "success"},
+			{"id": 2, "name": "lint", "status": "completed", "conclusion": "failure"},
+			{"id": 3, "name": "docs", "status": "completed", "conclusion": "skipped"},
+			{"id": 4, "name": "test", "status": "in_progress"}
+		]}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, SHA: "d74fd51"})()
+
+	runs, err := CheckRuns(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Len(t, runs, 6)
+
+	passed, failed, pending, failedNames, err := ChecksSummary(context.Background(), "d74fd51")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, passed)
+	assert.Equal(t, 2, failed)
+	assert.Equal(t, 2, pending)
+	assert.Equal(t, []string{"lint", "e2e"}, failedNames)
+
+	_, _, _, _, err = ChecksSummary(context.Background(), "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"time"
+)
+
+// now and after are replaced in tests to control time
+var (
+	now   = time.Now
+	after = time.After
+)
+
+// sleep waits for d to elapse, it returns early with the context error when ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-after(d):
+		return nil
+	}
+}
+
+const (
+	pollInitialInterval = 5 * time.Second
+	pollMaxInterval     = time.Minute
+)
+
+// poll calls check with an exponential backoff until it reports being done, returns an error, or timeout elapses.
+// It returns whether check reported being done before the timeout
+func poll(ctx context.Context, timeout time.Duration, check func() (done bool, retryAfter time.Duration, err error)) (bool, error) {
+	deadline := now().Add(timeout)
+	interval := pollInitialInterval
+	for {
+		done, retryAfter, err := check()
+		if done || err != nil {
+			return done, err
+		}
+		remaining := deadline.Sub(now())
+		if remaining <= 0 {
+			return false, nil
+		}
+		wait := interval
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if wait > remaining {
+			wait = remaining
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return false, err
+		}
+		interval *= 2
+		if interval > pollMaxInterval {
+			interval = pollMaxInterval
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// codeOwnersLocations are the locations GitHub looks for a CODEOWNERS file at, in order
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	rule   gitignoreRule
+	owners []string
+}
+
+// matches returns whether the rule matches the path or one of its parent directories
+func (r codeOwnersRule) matches(p string) bool {
+	parts := strings.Split(p, "/")
+	for i := 1; i < len(parts); i++ {
+		if r.rule.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.rule.match(p, false)
+}
+
+// CodeOwnersRules holds the rules of a CODEOWNERS file
+type CodeOwnersRules struct {
+	rules []codeOwnersRule
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file, patterns follow the .gitignore syntax
+func ParseCodeOwners(content string) *CodeOwnersRules {
+	c := &CodeOwnersRules{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		rule, ok := parseGitignoreRule("", fields[0])
+		if !ok {
+			continue
+		}
+		c.rules = append(c.rules, codeOwnersRule{rule: rule, owners: fields[1:]})
+	}
+	return c
+}
+
+// OwnersFor returns the owners of a slash separated path relative to the repository root, as written in the CODEOWNERS file:
+// users as @login, teams as @org/team or email addresses. The last matching rule wins, a rule without owners
+// makes the path unowned. nil is returned when no rule matches
+func (c *CodeOwnersRules) OwnersFor(path string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].matches(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// CodeOwners fetches and parses the CODEOWNERS file of the repository running the workflow, at the current ref.
+// An empty rule set is returned when the repository has no CODEOWNERS file
+func CodeOwners(ctx context.Context) (*CodeOwnersRules, error) {
+	for _, location := range codeOwnersLocations {
+		file, _, resp, err := GitHub.Repositories.GetContents(ctx, Context.Repo.Owner, Context.Repo.Repo, location, &github.RepositoryContentGetOptions{Ref: Context.SHA})
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %v", location, err)
+		}
+		content, err := file.GetContent()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", location, err)
+		}
+		return ParseCodeOwners(content), nil
+	}
+	return &CodeOwnersRules{}, nil
+}
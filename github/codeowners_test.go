@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCodeOwners = `# default owners
+*       @actions-go/maintainers
+
+*.go    @tjamet @actions-go/go-reviewers # inline comment
+/docs/  docs@example.com
+docs/generated/
+`
+
+func TestCodeOwners(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/.github/CODEOWNERS", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/CODEOWNERS", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "some-sha", r.URL.Query().Get("ref"))
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": "%s"}`, base64.StdEncoding.EncodeToString([]byte(testCodeOwners)))
+	})
+	mux.HandleFunc("/repos/actions-go/empty/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{SHA: "some-sha", Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	owners, err := CodeOwners(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@actions-go/maintainers"}, owners.OwnersFor("README.md"))
+	assert.Equal(t, []string{"@tjamet", "@actions-go/go-reviewers"}, owners.OwnersFor("github/github.go"), "last match wins, users and teams are kept")
+	assert.Equal(t, []string{"docs@example.com"}, owners.OwnersFor("docs/index.md"))
+	assert.Equal(t, []string{"docs@example.com"}, owners.OwnersFor("docs/example.go"), "the later docs rule takes precedence over *.go")
+	assert.Equal(t, []string{}, owners.OwnersFor("docs/generated/api.md"), "a rule without owners unsets them")
+
+	t.Run("without CODEOWNERS the rule set is empty", func(t *testing.T) {
+		Context.Repo.Repo = "empty"
+		owners, err := CodeOwners(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, owners.OwnersFor("README.md"))
+	})
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ActorPermission returns the permission of the user that triggered the workflow on the repository running it: admin, write, read or none.
+// Users without access to the repository, for example fork pull requests authors, have the none permission
+func ActorPermission(ctx context.Context) (string, error) {
+	actor := Context.Actor
+	if actor == "" {
+		return "", fmt.Errorf("unable to get the actor permission: GITHUB_ACTOR is not set")
+	}
+	level, resp, err := GitHub.Repositories.GetPermissionLevel(ctx, Context.Repo.Owner, Context.Repo.Repo, actor)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "none", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get the permission of %s: %v", actor, PermissionError(resp, err))
+	}
+	return level.GetPermission(), nil
+}
+
+// ActorCanWrite returns whether the user that triggered the workflow can push to the repository running it, see ActorPermission.
+// Use it to restrict comment commands to maintainers
+func ActorCanWrite(ctx context.Context) (bool, error) {
+	permission, err := ActorPermission(ctx)
+	if err != nil {
+		return false, err
+	}
+	return permission == "admin" || permission == "write", nil
+}
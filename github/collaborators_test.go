@@ -0,0 +1,51 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActorPermission(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/collaborators/", func(w http.ResponseWriter, r *http.Request) {
+		user := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/actions-go/toolkit/collaborators/"), "/permission")
+		if user == "outsider" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "outsider is not a user"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"permission": "%s", "user": {"login": "%s"}}`, user, user)
+	})
+	defer mockGitHub(mux)()
+
+	for actor, expected := range map[string]struct {
+		permission string
+		canWrite   bool
+	}{
+		"admin":    {"admin", true},
+		"write":    {"write", true},
+		"read":     {"read", false},
+		"none":     {"none", false},
+		"outsider": {"none", false},
+	} {
+		restore := mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, Actor: actor})
+		permission, err := ActorPermission(context.Background())
+		assert.NoError(t, err, actor)
+		assert.Equal(t, expected.permission, permission, actor)
+		canWrite, err := ActorCanWrite(context.Background())
+		assert.NoError(t, err, actor)
+		assert.Equal(t, expected.canWrite, canWrite, actor)
+		restore()
+	}
+
+	t.Run("an actor is required", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+		_, err := ActorPermission(context.Background())
+		assert.Error(t, err)
+	})
+}
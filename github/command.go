@@ -0,0 +1,87 @@
+package github
+
+import (
+	"strings"
+)
+
+// Command is a slash command parsed from a comment, such as /deploy staging --force
+type Command struct {
+	Name  string
+	Args  []string
+	Flags map[string]string
+}
+
+// splitCommandLine splits line in words, separated by spaces. Single and double quotes group words
+func splitCommandLine(line string) []string {
+	words := []string{}
+	word := strings.Builder{}
+	inWord := false
+	var quote rune
+	for _, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// parseCommand parses the first line of body as a command when it starts with prefix
+func parseCommand(prefix, body string) (*Command, bool) {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(body), "\n", 2)[0])
+	if prefix == "" || !strings.HasPrefix(line, prefix) {
+		return nil, false
+	}
+	words := splitCommandLine(strings.TrimPrefix(line, prefix))
+	if len(words) == 0 {
+		return nil, false
+	}
+	cmd := &Command{Name: words[0], Args: []string{}, Flags: map[string]string{}}
+	for i := 1; i < len(words); i++ {
+		w := words[i]
+		if !strings.HasPrefix(w, "--") || w == "--" {
+			cmd.Args = append(cmd.Args, w)
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(w, "--"), "=", 2)
+		if len(parts) == 2 {
+			cmd.Flags[parts[0]] = parts[1]
+			continue
+		}
+		value := ""
+		if i+1 < len(words) && !strings.HasPrefix(words[i+1], "--") {
+			i++
+			value = words[i]
+		}
+		cmd.Flags[parts[0]] = value
+	}
+	return cmd, true
+}
+
+// ParseCommand parses the body of the comment that triggered the workflow as a command when it starts with prefix.
+// The first word following prefix is the command name, the next ones its arguments and flags.
+// Flags are written --flag=value or --flag value, a flag followed by another one or ending the command has an empty value,
+// quotes group words. ok is false when the event has no comment or the comment is not a command
+func ParseCommand(prefix string) (*Command, bool) {
+	if Context.Payload.Comment == nil {
+		return nil, false
+	}
+	return parseCommand(prefix, Context.Payload.Comment.GetBody())
+}
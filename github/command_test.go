@@ -0,0 +1,43 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCommand(t *testing.T) {
+	defer setEnv(map[string]string{
+		"GITHUB_EVENT_NAME": "issue_comment",
+		"GITHUB_EVENT_PATH": "issue_comment_event.json",
+	})()
+	defer mockContext(ParseActionEnv())()
+
+	cmd, ok := ParseCommand("/")
+	require.True(t, ok)
+	assert.Equal(t, &Command{Name: "deploy", Args: []string{"staging"}, Flags: map[string]string{"force": ""}}, cmd)
+
+	_, ok = ParseCommand("!")
+	assert.False(t, ok)
+
+	cmd, ok = parseCommand("/", "  /release \"v1.2.0 rc\" 'main' --notes \"first release\" --draft --target=prod --\n\nmore details")
+	require.True(t, ok)
+	assert.Equal(t, "release", cmd.Name)
+	assert.Equal(t, []string{"v1.2.0 rc", "main", "--"}, cmd.Args)
+	assert.Equal(t, map[string]string{"notes": "first release", "draft": "", "target": "prod"}, cmd.Flags)
+
+	cmd, ok = parseCommand("@bot ", "@bot label \"\" bug")
+	require.True(t, ok)
+	assert.Equal(t, "label", cmd.Name)
+	assert.Equal(t, []string{"", "bug"}, cmd.Args)
+
+	_, ok = parseCommand("/", "looks good to me /deploy")
+	assert.False(t, ok)
+	_, ok = parseCommand("/", "/")
+	assert.False(t, ok)
+
+	Context.Payload.Comment = nil
+	_, ok = ParseCommand("/")
+	assert.False(t, ok)
+}
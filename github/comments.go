@@ -0,0 +1,110 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/google/go-github/v32/github"
+)
+
+// commentMarker returns the hidden HTML comment identifying comments created by UpsertComment
+func commentMarker(marker string) string {
+	return fmt.Sprintf("<!-- %s -->", marker)
+}
+
+// findComment returns the first comment of an issue authored by login and containing marker, nil when there is none
+func findComment(ctx context.Context, number int, login, marker string) (*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := GitHub.Issues.ListComments(ctx, Context.Repo.Owner, Context.Repo.Repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of issue %d: %v", number, err)
+		}
+		for _, c := range comments {
+			if c.GetUser().GetLogin() == login && strings.Contains(c.GetBody(), marker) {
+				return c, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// UpsertComment creates or updates a comment on an issue or pull request of the repository running the workflow.
+// Comments are identified by marker, hidden in the comment body, so that each run updates the same comment.
+// Only comments authored by the authenticated user are updated, see AuthenticatedLogin
+func UpsertComment(ctx context.Context, number int, marker, body string) (*github.IssueComment, error) {
+	login, err := AuthenticatedLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	marker = commentMarker(marker)
+	existing, err := findComment(ctx, number, login, marker)
+	if err != nil {
+		return nil, err
+	}
+	comment := &github.IssueComment{Body: github.String(marker + "\n" + body)}
+	var result *github.IssueComment
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		if existing != nil {
+			result, resp, err = GitHub.Issues.EditComment(ctx, Context.Repo.Owner, Context.Repo.Repo, existing.GetID(), comment)
+		} else {
+			result, resp, err = GitHub.Issues.CreateComment(ctx, Context.Repo.Owner, Context.Repo.Repo, number, comment)
+		}
+		return resp, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to comment issue %d: %v", number, err)
+	}
+	return result, nil
+}
+
+// commentMaxLength is the maximum length of a comment body
+const commentMaxLength = 65536
+
+// renderStatusTable renders a markdown table of at most limit bytes, truncating rows with a note when it is too long
+func renderStatusTable(headers []string, rows [][]string, limit int) string {
+	render := func(n int) string {
+		t := core.NewTableBuilder().Header(headers...)
+		for _, row := range rows[:n] {
+			t.Row(row...)
+		}
+		s := t.Render()
+		if n < len(rows) {
+			s += fmt.Sprintf("\n_%d more rows have been truncated._\n", len(rows)-n)
+		}
+		return s
+	}
+	if s := render(len(rows)); len(s) <= limit {
+		return s
+	}
+	// find the highest number of rows that fits
+	low, high := 0, len(rows)-1
+	for low < high {
+		mid := (low + high + 1) / 2
+		if len(render(mid)) <= limit {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return render(low)
+}
+
+// UpsertStatusTable renders a markdown table and creates or updates it as a comment on the issue or pull request that triggered the workflow,
+// see UpsertComment. Rows that do not fit in the maximum comment length are truncated with a note
+func UpsertStatusTable(ctx context.Context, marker string, headers []string, rows [][]string) error {
+	number := Context.Issue.Number
+	if number == 0 {
+		return fmt.Errorf("unable to comment: the workflow has not been triggered by an issue or a pull request")
+	}
+	limit := commentMaxLength - len(commentMarker(marker)) - 1
+	_, err := UpsertComment(ctx, number, marker, renderStatusTable(headers, rows, limit))
+	return err
+}
@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertComment(t *testing.T) {
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	comments := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"id": 1, "body": "<!-- coverage -->\nfrom someone else", "user": {"login": "octocat"}},
+			{"id": 2, "body": "unrelated comment", "user": {"login": "github-actions[bot]"}},
+			{"id": 3, "body": "<!-- coverage -->\nprevious report", "user": {"login": "github-actions[bot]"}}
+		]`)
+	}
+	user := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "github-actions[bot]"}`)
+	}
+
+	t.Run("own prior comments are updated", func(t *testing.T) {
+		edited := &github.IssueComment{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", user)
+		mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", comments)
+		mux.HandleFunc("/repos/actions-go/toolkit/issues/comments/3", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(edited))
+			edited.ID = github.Int64(3)
+			json.NewEncoder(w).Encode(edited)
+		})
+		defer mockGitHub(mux)()
+
+		comment, err := UpsertComment(context.Background(), 1, "coverage", "new report")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), comment.GetID())
+		assert.Equal(t, "<!-- coverage -->\nnew report", edited.GetBody())
+	})
+
+	t.Run("a comment is created when there is none of our own", func(t *testing.T) {
+		created := &github.IssueComment{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"login": "my-app[bot]"}`)
+		})
+		mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				comments(w, r)
+				return
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(created))
+			created.ID = github.Int64(4)
+			json.NewEncoder(w).Encode(created)
+		})
+		defer mockGitHub(mux)()
+
+		comment, err := UpsertComment(context.Background(), 1, "coverage", "new report")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), comment.GetID())
+		assert.Equal(t, "<!-- coverage -->\nnew report", created.GetBody())
+	})
+}
+
+func TestUpsertStatusTable(t *testing.T) {
+	comments := []*github.IssueComment{}
+	created, edited := 0, []int64{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		// the workflow token can't read the authenticated user
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/12/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(comments)
+			return
+		}
+		created++
+		c := &github.IssueComment{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(c))
+		c.ID, c.User = github.Int64(7), &github.User{Login: github.String("github-actions[bot]")}
+		comments = append(comments, c)
+		json.NewEncoder(w).Encode(c)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/comments/7", func(w http.ResponseWriter, r *http.Request) {
+		edited = append(edited, 7)
+		c := &github.IssueComment{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(c))
+		comments[0].Body = c.Body
+		json.NewEncoder(w).Encode(comments[0])
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, Issue: ActionIssue{Number: 12}})()
+
+	require.NoError(t, UpsertStatusTable(context.Background(), "tests", []string{"Suite", "Result"}, [][]string{{"core", "failed"}}))
+	require.NoError(t, UpsertStatusTable(context.Background(), "tests", []string{"Suite", "Result"}, [][]string{{"core", "passed"}, {"github", "passed"}}))
+	assert.Equal(t, 1, created)
+	assert.Equal(t, []int64{7}, edited, "the comment created by the first run must be updated")
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, "<!-- tests -->\n| Suite | Result |\n| --- | --- |\n| core | passed |\n| github | passed |\n", comments[0].GetBody())
+	}
+
+	t.Run("tables too long for a comment are truncated", func(t *testing.T) {
+		rows := make([][]string, 10000)
+		for i := range rows {
+			rows[i] = []string{fmt.Sprintf("suite %d", i), "passed"}
+		}
+		require.NoError(t, UpsertStatusTable(context.Background(), "tests", []string{"Suite", "Result"}, rows))
+		body := comments[0].GetBody()
+		assert.True(t, len(body) <= commentMaxLength)
+		assert.True(t, len(body) > commentMaxLength-100, "as many rows as possible must be kept")
+		assert.Regexp(t, "\n_[0-9]+ more rows have been truncated._\n$", body)
+	})
+
+	t.Run("issues or pull requests are required", func(t *testing.T) {
+		Context.Issue.Number = 0
+		assert.Error(t, UpsertStatusTable(context.Background(), "tests", []string{"Suite"}, nil))
+	})
+}
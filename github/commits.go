@@ -0,0 +1,157 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// CommitVerification returns whether the commit signature has been verified by GitHub.
+// reason is the verification status reported by GitHub, for example valid, unsigned or unknown_key
+func CommitVerification(ctx context.Context, sha string) (verified bool, reason string, err error) {
+	commit, _, err := GitHub.Repositories.GetCommit(ctx, Context.Repo.Owner, Context.Repo.Repo, sha)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get commit %s: %v", sha, err)
+	}
+	verification := commit.GetCommit().GetVerification()
+	return verification.GetVerified(), verification.GetReason(), nil
+}
+
+// CurrentCommitVerified returns whether the signature of the commit being processed, see HeadSHA, has been verified by GitHub
+func CurrentCommitVerified(ctx context.Context) (verified bool, reason string, err error) {
+	return CommitVerification(ctx, HeadSHA())
+}
+
+// payloadAuthor copies an author of a push event head commit, setting its date to the commit timestamp when missing
+func payloadAuthor(author *github.CommitAuthor, timestamp *github.Timestamp) *github.CommitAuthor {
+	if author == nil {
+		return nil
+	}
+	a := *author
+	if a.Date == nil && timestamp != nil {
+		a.Date = &timestamp.Time
+	}
+	return &a
+}
+
+// commitFromPayload converts the head commit of a push event to a commit
+func commitFromPayload(head *github.HeadCommit) *github.Commit {
+	return &github.Commit{
+		SHA:       head.ID,
+		Message:   head.Message,
+		Author:    payloadAuthor(head.Author, head.Timestamp),
+		Committer: payloadAuthor(head.Committer, head.Timestamp),
+		Tree:      &github.Tree{SHA: head.TreeID},
+		URL:       head.URL,
+	}
+}
+
+// CurrentCommit returns the commit being processed, see HeadSHA.
+// For push events, the head commit of the payload is returned without calling the API
+func CurrentCommit(ctx context.Context) (*github.Commit, error) {
+	sha := HeadSHA()
+	if Context.Payload.PushEvent != nil {
+		if head := Context.Payload.GetHeadCommit(); head.GetID() == sha && sha != "" {
+			return commitFromPayload(head), nil
+		}
+	}
+	commit, _, err := GitHub.Repositories.GetCommit(ctx, Context.Repo.Owner, Context.Repo.Repo, sha)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %v", sha, err)
+	}
+	c := commit.GetCommit()
+	if c.SHA == nil {
+		c.SHA = commit.SHA
+	}
+	return c, nil
+}
+
+// CommitMessage returns the message of the commit being processed, see CurrentCommit
+func CommitMessage(ctx context.Context) (string, error) {
+	commit, err := CurrentCommit(ctx)
+	if err != nil {
+		return "", err
+	}
+	return commit.GetMessage(), nil
+}
+
+// CommitAuthor returns the author of the commit being processed, see CurrentCommit
+func CommitAuthor(ctx context.Context) (*github.CommitAuthor, error) {
+	commit, err := CurrentCommit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return commit.GetAuthor(), nil
+}
+
+// compareMaxCommits is the number of commits the compare API returns at most
+const compareMaxCommits = 250
+
+// historySince lists the commits reachable from head that were committed after the merge base, oldest first
+func historySince(ctx context.Context, head string, mergeBase *github.RepositoryCommit) ([]*github.RepositoryCommit, error) {
+	opts := &github.CommitsListOptions{SHA: head, ListOptions: github.ListOptions{PerPage: 100}}
+	if date := mergeBase.GetCommit().GetCommitter().GetDate(); !date.IsZero() {
+		opts.Since = date
+	}
+	commits := []*github.RepositoryCommit{}
+	for {
+		page, resp, err := GitHub.Repositories.ListCommits(ctx, Context.Repo.Owner, Context.Repo.Repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits of %s: %v", head, err)
+		}
+		done := false
+		for _, c := range page {
+			if c.GetSHA() == mergeBase.GetSHA() {
+				done = true
+				break
+			}
+			commits = append(commits, c)
+		}
+		if done || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+// CommitsBetweenEach calls fn with each commit of head that is not in base, oldest first, until fn returns an error.
+// The compare API returns at most 250 commits, when it is truncated the commits are listed from the history of head
+// since the merge base instead and truncated is true. Commits of merged branches older than the merge base are then missed
+func CommitsBetweenEach(ctx context.Context, base, head string, fn func(*github.RepositoryCommit) error) (truncated bool, err error) {
+	comparison, _, err := GitHub.Repositories.CompareCommits(ctx, Context.Repo.Owner, Context.Repo.Repo, base, head)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare %s...%s: %v", base, head, err)
+	}
+	commits := comparison.Commits
+	if comparison.GetTotalCommits() > len(commits) && len(commits) >= compareMaxCommits {
+		truncated = true
+		commits, err = historySince(ctx, head, comparison.GetMergeBaseCommit())
+		if err != nil {
+			return truncated, err
+		}
+	}
+	for _, c := range commits {
+		if err := fn(c); err != nil {
+			return truncated, err
+		}
+	}
+	return truncated, nil
+}
+
+// CommitsBetween returns the commits of head that are not in base, oldest first, see CommitsBetweenEach
+func CommitsBetween(ctx context.Context, base, head string) ([]*github.RepositoryCommit, error) {
+	commits := []*github.RepositoryCommit{}
+	_, err := CommitsBetweenEach(ctx, base, head, func(c *github.RepositoryCommit) error {
+		commits = append(commits, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
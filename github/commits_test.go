@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitVerification(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/signed", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "signed", "commit": {"verification": {"verified": true, "reason": "valid"}}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/unsigned", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "unsigned", "commit": {"verification": {"verified": false, "reason": "unsigned"}}}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, SHA: "unsigned"})()
+
+	verified, reason, err := CommitVerification(context.Background(), "signed")
+	assert.NoError(t, err)
+	assert.True(t, verified)
+	assert.Equal(t, "valid", reason)
+
+	verified, reason, err = CurrentCommitVerified(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, verified)
+	assert.Equal(t, "unsigned", reason)
+
+	Context.Payload.PullRequest = &github.PullRequest{Head: &github.PullRequestBranch{SHA: github.String("signed")}}
+	verified, _, err = CurrentCommitVerified(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	_, _, err = CommitVerification(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestCurrentCommit(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/d74fd518cf0410699c6b748924727686c1606d00", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"sha": "d74fd518cf0410699c6b748924727686c1606d00", "commit": {
+			"message": "Create blank.yml\n\nfrom the API",
+			"author": {"name": "Thibault Jamet", "email": "tjamet@users.noreply.github.com", "date": "2020-01-17T19:21:57Z"}
+		}}`)
+	})
+	defer mockGitHub(mux)()
+
+	t.Run("push events use the payload head commit", func(t *testing.T) {
+		defer setEnv(map[string]string{
+			"GITHUB_EVENT_NAME": "push",
+			"GITHUB_EVENT_PATH": "push_event.json",
+			"GITHUB_SHA":        "d74fd518cf0410699c6b748924727686c1606d00",
+		})()
+		defer mockContext(ParseActionEnv())()
+
+		commit, err := CurrentCommit(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "d74fd518cf0410699c6b748924727686c1606d00", commit.GetSHA())
+		message, err := CommitMessage(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "Create blank.yml", message)
+		author, err := CommitAuthor(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "Thibault Jamet", author.GetName())
+		assert.True(t, author.GetDate().Equal(time.Date(2020, 1, 17, 19, 21, 57, 0, time.UTC)))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("other events use the API", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, SHA: "d74fd518cf0410699c6b748924727686c1606d00"})()
+		commit, err := CurrentCommit(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "d74fd518cf0410699c6b748924727686c1606d00", commit.GetSHA())
+		assert.Equal(t, "Create blank.yml\n\nfrom the API", commit.GetMessage())
+		assert.Equal(t, "tjamet@users.noreply.github.com", commit.GetAuthor().GetEmail())
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestCommitsBetween(t *testing.T) {
+	// history of main, newest first: c300 ... c1, then the v1 merge base and older commits
+	history := []*github.RepositoryCommit{}
+	for i := 300; i > 0; i-- {
+		history = append(history, &github.RepositoryCommit{SHA: github.String(fmt.Sprintf("c%d", i))})
+	}
+	history = append(history, &github.RepositoryCommit{SHA: github.String("v1")}, &github.RepositoryCommit{SHA: github.String("c0")})
+	pages := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/v1...v1.1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_commits": 2, "merge_base_commit": {"sha": "v1"}, "commits": [{"sha": "c1"}, {"sha": "c2"}]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/compare/v1...main", func(w http.ResponseWriter, r *http.Request) {
+		comparison := &github.CommitsComparison{
+			TotalCommits: github.Int(300),
+			MergeBaseCommit: &github.RepositoryCommit{SHA: github.String("v1"), Commit: &github.Commit{
+				Committer: &github.CommitAuthor{Date: &time.Time{}},
+			}},
+		}
+		for i := 1; i <= 250; i++ {
+			comparison.Commits = append(comparison.Commits, &github.RepositoryCommit{SHA: github.String(fmt.Sprintf("c%d", i))})
+		}
+		json.NewEncoder(w).Encode(comparison)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/commits", func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		assert.Equal(t, "main", r.URL.Query().Get("sha"))
+		page := 1
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		end := page * 100
+		if end < len(history) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		} else {
+			end = len(history)
+		}
+		json.NewEncoder(w).Encode(history[(page-1)*100 : end])
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	commits, err := CommitsBetween(context.Background(), "v1", "v1.1")
+	require.NoError(t, err)
+	if assert.Len(t, commits, 2) {
+		assert.Equal(t, "c1", commits[0].GetSHA())
+	}
+	assert.Equal(t, 0, pages)
+
+	shas := []string{}
+	truncated, err := CommitsBetweenEach(context.Background(), "v1", "main", func(c *github.RepositoryCommit) error {
+		shas = append(shas, c.GetSHA())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, 4, pages)
+	if assert.Len(t, shas, 300) {
+		assert.Equal(t, "c1", shas[0], "commits are listed oldest first")
+		assert.Equal(t, "c300", shas[299])
+	}
+
+	t.Run("errors returned by fn stop the iteration", func(t *testing.T) {
+		calls := 0
+		_, err := CommitsBetweenEach(context.Background(), "v1", "v1.1", func(c *github.RepositoryCommit) error {
+			calls++
+			return fmt.Errorf("stop")
+		})
+		assert.EqualError(t, err, "stop")
+		assert.Equal(t, 1, calls)
+	})
+}
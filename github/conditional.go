@@ -0,0 +1,100 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+type cachedResponse struct {
+	etag       string
+	statusCode int
+	status     string
+	header     http.Header
+	body       []byte
+}
+
+// conditionalTransport caches GET responses by URL, media type and credentials, and replays them when GitHub answers 304 Not Modified
+type conditionalTransport struct {
+	base  http.RoundTripper
+	lock  sync.Mutex
+	cache map[string]cachedResponse
+}
+
+func newConditionalTransport(base http.RoundTripper) *conditionalTransport {
+	return &conditionalTransport{
+		base:  base,
+		cache: map[string]cachedResponse{},
+	}
+}
+
+// cacheKey returns the key the response to req is cached under. Responses depend on the requested media type and on
+// the permissions of the credentials, the credentials are hashed so that they are not kept in the cache
+func cacheKey(req *http.Request) string {
+	auth := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return req.URL.String() + "\n" + req.Header.Get("Accept") + "\n" + hex.EncodeToString(auth[:])
+}
+
+func (t *conditionalTransport) get(key string) (cachedResponse, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	c, ok := t.cache[key]
+	return c, ok
+}
+
+func (t *conditionalTransport) set(key string, c cachedResponse) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.cache[key] = c
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return t.base.RoundTrip(req)
+	}
+	key := cacheKey(req)
+	cached, ok := t.get(key)
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		header := cached.header.Clone()
+		// keep fresh values, in particular the rate limit ones
+		for k, v := range resp.Header {
+			header[k] = v
+		}
+		resp.StatusCode = cached.statusCode
+		resp.Status = cached.status
+		resp.Header = header
+		resp.Body = ioutil.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		return resp, nil
+	}
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.set(key, cachedResponse{
+		etag:       etag,
+		statusCode: resp.StatusCode,
+		status:     resp.Status,
+		header:     resp.Header.Clone(),
+		body:       body,
+	})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
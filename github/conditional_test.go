@@ -0,0 +1,87 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	calls := 0
+	notModified := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("ETag", `"some-etag"`)
+		if r.Header.Get("If-None-Match") == `"some-etag"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, `{"name": "toolkit", "owner": {"login": "actions"}}`)
+	}))
+	defer s.Close()
+
+	c := github.NewClient(github.WithConditionalRequests())
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+
+	for i := 0; i < 2; i++ {
+		repo, resp, err := c.Repositories.Get(context.Background(), "actions", "toolkit")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "toolkit", repo.GetName())
+		assert.Equal(t, "actions", repo.GetOwner().GetLogin())
+	}
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, notModified)
+
+	t.Run("without the option, responses are not cached", func(t *testing.T) {
+		calls, notModified = 0, 0
+		c := github.NewClient()
+		c.BaseURL, _ = url.Parse(s.URL + "/")
+		for i := 0; i < 2; i++ {
+			_, _, err := c.Repositories.Get(context.Background(), "actions", "toolkit")
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 0, notModified)
+	})
+
+	t.Run("responses are cached per media type and credentials", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"same-etag"`)
+			if r.Header.Get("If-None-Match") == `"same-etag"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, r.Header.Get("Accept")+" "+r.Header.Get("Authorization"))
+		}))
+		defer s.Close()
+
+		c := github.NewHTTPClient(github.WithConditionalRequests())
+		get := func(accept, auth string) string {
+			req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept", accept)
+			req.Header.Set("Authorization", auth)
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return string(b)
+		}
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, "application/json token first", get("application/json", "token first"))
+			assert.Equal(t, "application/vnd.github.v3.raw token first", get("application/vnd.github.v3.raw", "token first"))
+			assert.Equal(t, "application/json token second", get("application/json", "token second"))
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/google/go-github/v32/github"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPathInput is the input overriding the path of the configuration file loaded by LoadRepoConfig
+const ConfigPathInput = "config-path"
+
+// LoadRepoConfig reads a configuration file at the commit being processed in the repository running the workflow
+// and unmarshals it into out. Files with a .json extension are decoded as JSON, others as YAML.
+// When the ConfigPathInput input is set, it overrides path.
+// An ErrNotFound is returned when the file does not exist so that callers can fall back to defaults
+func LoadRepoConfig(ctx context.Context, path string, out interface{}) error {
+	if override, ok := core.GetInput(ConfigPathInput); ok && override != "" {
+		path = override
+	}
+	file, _, resp, err := GitHub.Repositories.GetContents(ctx, Context.Repo.Owner, Context.Repo.Repo, path, &github.RepositoryContentGetOptions{Ref: Context.SHA})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return &ErrNotFound{Resource: "configuration file " + path, Err: err}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", path, err)
+	}
+	if file == nil {
+		return fmt.Errorf("failed to get %s: it is a directory", path)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return unmarshalConfig(path, []byte(content), out)
+}
+
+func unmarshalConfig(name string, content []byte, out interface{}) error {
+	var err error
+	if strings.EqualFold(path.Ext(name), ".json") {
+		err = json.Unmarshal(content, out)
+	} else {
+		err = yaml.Unmarshal(content, out)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration file %s: %v", name, err)
+	}
+	return nil
+}
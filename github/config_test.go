@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveContent(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"type": "file", "encoding": "base64", "content": "%s"}`, base64.StdEncoding.EncodeToString([]byte(content)))
+	}
+}
+
+func TestLoadRepoConfig(t *testing.T) {
+	type config struct {
+		Labels  []string `yaml:"labels" json:"labels"`
+		DaysAgo int      `yaml:"days-ago" json:"daysAgo"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/.github/stale.yml", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "d74fd51", r.URL.Query().Get("ref"))
+		serveContent("labels: [stale, wontfix]\ndays-ago: 30\n")(w, r)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/.github/stale.json", serveContent(`{"labels": ["stale"], "daysAgo": 7}`))
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/.github/malformed.yml", serveContent("labels: [stale\ndays-ago: 30\n"))
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/.github/missing.yml", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, SHA: "d74fd51"})()
+
+	c := config{}
+	require.NoError(t, LoadRepoConfig(context.Background(), ".github/stale.yml", &c))
+	assert.Equal(t, config{Labels: []string{"stale", "wontfix"}, DaysAgo: 30}, c)
+
+	t.Run("the path can be overridden from an input", func(t *testing.T) {
+		defer setEnv(map[string]string{"INPUT_CONFIG-PATH": ".github/stale.json"})()
+		c := config{}
+		require.NoError(t, LoadRepoConfig(context.Background(), ".github/stale.yml", &c))
+		assert.Equal(t, config{Labels: []string{"stale"}, DaysAgo: 7}, c)
+	})
+
+	t.Run("missing files return ErrNotFound", func(t *testing.T) {
+		err := LoadRepoConfig(context.Background(), ".github/missing.yml", &config{})
+		assert.IsType(t, &ErrNotFound{}, err)
+	})
+
+	t.Run("malformed files report the decoding error", func(t *testing.T) {
+		err := LoadRepoConfig(context.Background(), ".github/malformed.yml", &config{})
+		if assert.Error(t, err) {
+			_, notFound := err.(*ErrNotFound)
+			assert.False(t, notFound)
+			assert.Contains(t, err.Error(), "invalid configuration file .github/malformed.yml")
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// FileErrors gathers the errors of the files GetFiles failed to fetch, by path
+type FileErrors map[string]error
+
+func (e FileErrors) Error() string {
+	paths := make([]string, 0, len(e))
+	for p := range e {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	messages := make([]string, len(paths))
+	for i, p := range paths {
+		messages[i] = e[p].Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// GetFilesOption customises GetFiles
+type GetFilesOption func(*getFilesOptions)
+
+type getFilesOptions struct {
+	concurrency int
+	failFast    bool
+}
+
+// WithFilesConcurrency sets the number of files fetched in parallel, 4 by default
+func WithFilesConcurrency(n int) GetFilesOption {
+	return func(o *getFilesOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithFailFast stops fetching files at the first failure, which is returned alone
+func WithFailFast() GetFilesOption {
+	return func(o *getFilesOptions) {
+		o.failFast = true
+	}
+}
+
+// getFile fetches the content of the file at p in a repository at ref with the contents API.
+// Files larger than the 1MB the contents API returns are read from their blob
+func getFile(ctx context.Context, owner, repo, ref, p string) (RepositoryFile, error) {
+	file, _, resp, err := GitHub.Repositories.GetContents(ctx, owner, repo, p, &github.RepositoryContentGetOptions{Ref: ref})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return RepositoryFile{}, &ErrNotFound{Resource: fmt.Sprintf("%s in %s/%s@%s", p, owner, repo, ref), Err: err}
+	}
+	if err != nil {
+		return RepositoryFile{}, fmt.Errorf("failed to get %s: %v", p, err)
+	}
+	if file == nil {
+		return RepositoryFile{}, fmt.Errorf("failed to get %s: it is a directory", p)
+	}
+	var data []byte
+	if file.GetEncoding() == "none" {
+		data, _, err = GitHub.Git.GetBlobRaw(ctx, owner, repo, file.GetSHA())
+	} else {
+		var content string
+		content, err = file.GetContent()
+		data = []byte(content)
+	}
+	if err != nil {
+		return RepositoryFile{}, fmt.Errorf("failed to get %s: %v", p, err)
+	}
+	return RepositoryFile{Path: p, FileInfo: blobFileInfo{name: p, size: int64(len(data))}, Data: data}, nil
+}
+
+// GetFiles fetches the files at paths in a repository at ref in parallel, with the contents API.
+// When the exact files needed are known, this avoids downloading the whole repository tarball.
+// Files fetched successfully are returned along with the FileErrors of the others, missing files failing with an ErrNotFound.
+// With WithFailFast, fetching stops at the first failure which is returned with no file
+func GetFiles(ctx context.Context, owner, repo, ref string, paths []string, options ...GetFilesOption) (map[string]RepositoryFile, error) {
+	o := getFilesOptions{concurrency: 4}
+	for _, option := range options {
+		option(&o)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lock := sync.Mutex{}
+	files := map[string]RepositoryFile{}
+	errs := FileErrors{}
+	var first error
+	pending := make(chan string)
+	wg := sync.WaitGroup{}
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range pending {
+				f, err := getFile(ctx, owner, repo, ref, p)
+				lock.Lock()
+				if err != nil {
+					errs[p] = err
+					if o.failFast && first == nil {
+						first = err
+						cancel()
+					}
+				} else {
+					files[p] = f
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, p := range paths {
+		select {
+		case pending <- p:
+		case <-ctx.Done():
+		}
+	}
+	close(pending)
+	wg.Wait()
+	if first != nil {
+		return nil, first
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return files, errs
+	}
+	return files, nil
+}
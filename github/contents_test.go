@@ -0,0 +1,69 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFiles(t *testing.T) {
+	lock := sync.Mutex{}
+	inFlight, maxInFlight := 0, 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/contents/", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		lock.Unlock()
+		defer func() {
+			lock.Lock()
+			inFlight--
+			lock.Unlock()
+		}()
+		assert.Equal(t, "v1.2.0", r.URL.Query().Get("ref"))
+		p := strings.TrimPrefix(r.URL.Path, "/repos/actions-go/toolkit/contents/")
+		switch p {
+		case "go.mod", "action.yml":
+			fmt.Fprintf(w, `{"type": "file", "path": %q, "encoding": "base64", "content": %q}`, p, base64.StdEncoding.EncodeToString([]byte("content of "+p)))
+		case "dist/index.js":
+			fmt.Fprint(w, `{"type": "file", "path": "dist/index.js", "encoding": "none", "content": "", "sha": "large-blob"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		}
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/blobs/large-blob", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "large content")
+	})
+	defer mockGitHub(mux)()
+	ctx := context.Background()
+
+	files, err := GetFiles(ctx, "actions-go", "toolkit", "v1.2.0", []string{"go.mod", "missing.txt", "dist/index.js"})
+	errs, ok := err.(FileErrors)
+	require.True(t, ok, err)
+	assert.Len(t, errs, 1)
+	assert.IsType(t, &ErrNotFound{}, errs["missing.txt"])
+	assert.Len(t, files, 2)
+	assert.Equal(t, "content of go.mod", string(files["go.mod"].Data))
+	assert.Equal(t, "go.mod", files["go.mod"].Path)
+	assert.Equal(t, "large content", string(files["dist/index.js"].Data))
+
+	maxInFlight = 0
+	files, err = GetFiles(ctx, "actions-go", "toolkit", "v1.2.0", []string{"go.mod", "action.yml", "dist/index.js"}, WithFilesConcurrency(2))
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+	assert.True(t, maxInFlight <= 2, "at most 2 files are fetched in parallel, got %d", maxInFlight)
+
+	files, err = GetFiles(ctx, "actions-go", "toolkit", "v1.2.0", []string{"missing.txt", "go.mod"}, WithFailFast(), WithFilesConcurrency(1))
+	assert.IsType(t, &ErrNotFound{}, err)
+	assert.Nil(t, files)
+}
@@ -64,6 +64,21 @@ type WebhookPayload struct {
 	Sender       *github.Contributor  `json:"sender"`
 	Action       string               `json:"action"`
 	Installation *github.Installation `json:"installation"`
+	// Schedule is the cron expression that triggered a schedule event
+	Schedule string `json:"schedule,omitempty"`
+	// Inputs are the inputs of workflow_dispatch and workflow_call events, values keep their declared type
+	Inputs map[string]interface{} `json:"inputs,omitempty"`
+	// WorkflowRun is the run that triggered a workflow_run event
+	WorkflowRun *github.WorkflowRun `json:"workflow_run,omitempty"`
+	// MergeGroup is the group of pull requests that triggered a merge_group event
+	MergeGroup *MergeGroup `json:"merge_group,omitempty"`
+	// Environment is the environment being deployed to for deployment_protection_rule events
+	Environment string `json:"environment,omitempty"`
+	// DeploymentCallbackURL is the URL to review the deployment with for deployment_protection_rule events
+	DeploymentCallbackURL string             `json:"deployment_callback_url,omitempty"`
+	Deployment            *github.Deployment `json:"deployment,omitempty"`
+	// Comment is the comment that triggered an issue_comment or pull_request_review_comment event
+	Comment *github.IssueComment `json:"comment,omitempty"`
 }
 
 type ActionIssue struct {
@@ -145,3 +160,31 @@ func ParseActionEnv() ActionContext {
 	}
 	return ctx
 }
+
+// IsScheduled returns whether the workflow has been triggered by a schedule
+func IsScheduled() bool {
+	return Context.EventName == "schedule"
+}
+
+// ScheduleCron returns the cron expression that triggered the workflow.
+// As a workflow can have several schedules, this allows running different logic for each of them.
+// ok is false when the workflow was not triggered by a schedule
+func ScheduleCron() (string, bool) {
+	if !IsScheduled() || Context.Payload.Schedule == "" {
+		return "", false
+	}
+	return Context.Payload.Schedule, true
+}
+
+// HeadSHA returns the SHA of the commit being processed.
+// For pull requests, this is the head commit of the pull request rather than the merge commit GITHUB_SHA points to.
+// For merge queues, this is the head commit of the merge group
+func HeadSHA() string {
+	if sha := Context.Payload.PullRequest.GetHead().GetSHA(); sha != "" {
+		return sha
+	}
+	if sha := MergeGroupHeadSHA(); sha != "" {
+		return sha
+	}
+	return Context.SHA
+}
@@ -99,4 +99,26 @@ func TestContext(t *testing.T) {
 	testEventParser(t, "label_event.json")
 	testEventParser(t, "milestone_event.json")
 	testEventParser(t, "push_event.json")
+	testEventParser(t, "workflow_run_event.json")
+	testEventParser(t, "workflow_call_event.json")
+	testEventParser(t, "merge_group_event.json")
+	testEventParser(t, "deployment_protection_rule_event.json")
+	testEventParser(t, "issue_comment_event.json")
+}
+
+func TestScheduleCron(t *testing.T) {
+	testEventParser(t, "schedule_event.json")
+
+	defer setEnv(map[string]string{"GITHUB_EVENT_NAME": "schedule", "GITHUB_EVENT_PATH": "schedule_event.json"})()
+	defer mockContext(ParseActionEnv())()
+	assert.True(t, IsScheduled())
+	cron, ok := ScheduleCron()
+	assert.True(t, ok)
+	assert.Equal(t, "*/15 * * * *", cron)
+
+	defer setEnv(map[string]string{"GITHUB_EVENT_NAME": "push", "GITHUB_EVENT_PATH": "push_event.json"})()
+	Context = ParseActionEnv()
+	assert.False(t, IsScheduled())
+	_, ok = ScheduleCron()
+	assert.False(t, ok)
 }
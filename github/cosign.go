@@ -0,0 +1,151 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// AssetSignatureOption customises the checks of VerifyAssetSignature
+type AssetSignatureOption func(*assetSignatureOptions)
+
+type assetSignatureOptions struct {
+	roots    *x509.CertPool
+	identity string
+}
+
+// WithCertificateRoots requires the signing certificate to chain to one of roots, typically the Fulcio root and
+// intermediate certificates. Keyless certificates are short lived, the chain is checked at the certificate issuance time
+func WithCertificateRoots(roots *x509.CertPool) AssetSignatureOption {
+	return func(o *assetSignatureOptions) {
+		o.roots = roots
+	}
+}
+
+// WithCertificateIdentity requires the signing certificate to be issued to identity, an email or URI subject
+// alternative name. For keyless signatures from workflows, this is the workflow reference URI
+func WithCertificateIdentity(identity string) AssetSignatureOption {
+	return func(o *assetSignatureOptions) {
+		o.identity = identity
+	}
+}
+
+// decodeMaybeBase64 returns b decoded when it is base64 encoded, as written by cosign, b untouched otherwise.
+// Surrounding spaces are only trimmed for decoding, raw signatures may start or end with such bytes
+func decodeMaybeBase64(b []byte) []byte {
+	trimmed := bytes.TrimSpace(b)
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(trimmed)))
+	n, err := base64.StdEncoding.Decode(decoded, trimmed)
+	if err != nil {
+		return b
+	}
+	return decoded[:n]
+}
+
+// signingKey returns the public key of a certificate or of a public key, PEM encoded
+func signingKey(b []byte, o assetSignatureOptions) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(decodeMaybeBase64(b))
+	if block == nil {
+		return nil, &ErrInvalidSignature{Reason: "the certificate or public key must be PEM encoded"}
+	}
+	if block.Type != "CERTIFICATE" {
+		if o.roots != nil || o.identity != "" {
+			return nil, &ErrInvalidSignature{Reason: "a certificate is required to check its roots or identity"}
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, &ErrInvalidSignature{Reason: fmt.Sprintf("malformed public key: %v", err)}
+		}
+		return key, nil
+	}
+	// anyone can issue a certificate, it is only trusted when it chains to known roots and is issued to the expected identity
+	if o.roots == nil || o.identity == "" {
+		return nil, &ErrInvalidSignature{Reason: "certificates must be checked with WithCertificateRoots and WithCertificateIdentity"}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, &ErrInvalidSignature{Reason: fmt.Sprintf("malformed certificate: %v", err)}
+	}
+	if !hasIdentity(cert, o.identity) {
+		return nil, &ErrInvalidSignature{Reason: fmt.Sprintf("the certificate is not issued to %s", o.identity)}
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:       o.roots,
+		CurrentTime: cert.NotBefore,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, &ErrInvalidSignature{Reason: fmt.Sprintf("untrusted certificate: %v", err)}
+	}
+	return cert.PublicKey, nil
+}
+
+func hasIdentity(cert *x509.Certificate, identity string) bool {
+	for _, email := range cert.EmailAddresses {
+		if email == identity {
+			return true
+		}
+	}
+	for _, u := range cert.URIs {
+		if u.String() == identity {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyECDSA(key *ecdsa.PublicKey, digest, sig []byte) bool {
+	var rs struct{ R, S *big.Int }
+	rest, err := asn1.Unmarshal(sig, &rs)
+	if err != nil || len(rest) > 0 {
+		return false
+	}
+	return ecdsa.Verify(key, digest, rs.R, rs.S)
+}
+
+// VerifyAssetSignature checks sig is a detached signature of asset from the key of cert, offline.
+// cert is either a PEM certificate, as issued by Fulcio in cosign's keyless flow, or a PEM public key, base64 encoded
+// or not. Certificates are only trusted with both WithCertificateRoots and WithCertificateIdentity.
+// sig is the signature written by cosign sign-blob, base64 encoded or raw. ECDSA, RSA and ed25519 keys are supported.
+// Transparency log inclusion is not checked. An ErrInvalidSignature is returned when the signature can't be trusted,
+// the context error when ctx is done before the asset is hashed
+func VerifyAssetSignature(ctx context.Context, asset, sig, cert []byte, options ...AssetSignatureOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	o := assetSignatureOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+	key, err := signingKey(cert, o)
+	if err != nil {
+		return err
+	}
+	sig = decodeMaybeBase64(sig)
+	digest := sha256.Sum256(asset)
+	valid := false
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = verifyECDSA(k, digest[:], sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, asset, sig)
+	default:
+		return &ErrInvalidSignature{Reason: fmt.Sprintf("unsupported key type %T", key)}
+	}
+	if !valid {
+		return &ErrInvalidSignature{Reason: "signature mismatch"}
+	}
+	return nil
+}
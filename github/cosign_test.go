@@ -0,0 +1,142 @@
+package github_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signer struct {
+	key  *ecdsa.PrivateKey
+	cert []byte
+	root *x509.CertPool
+}
+
+// newSigner issues a short lived code signing certificate the way Fulcio does for keyless signatures
+func newSigner(t *testing.T, identity string) signer {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	notBefore := time.Now().Add(-time.Hour)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sigstore"},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err = x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leaf := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		NotBefore:      notBefore,
+		NotAfter:       notBefore.Add(10 * time.Minute),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses: []string{identity},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	root := x509.NewCertPool()
+	root.AddCert(ca)
+	return signer{key: key, cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}), root: root}
+}
+
+func (s signer) sign(t *testing.T, asset []byte) []byte {
+	digest := sha256.Sum256(asset)
+	r, ss, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	require.NoError(t, err)
+	sig, err := asn1Signature(r, ss)
+	require.NoError(t, err)
+	return []byte(base64.StdEncoding.EncodeToString(sig))
+}
+
+func TestVerifyAssetSignature(t *testing.T) {
+	ctx := context.Background()
+	s := newSigner(t, "release@actions-go.dev")
+	asset := []byte("release binary")
+	sig := s.sign(t, asset)
+	b64Cert := []byte(base64.StdEncoding.EncodeToString(s.cert))
+
+	trusted := []github.AssetSignatureOption{github.WithCertificateRoots(s.root), github.WithCertificateIdentity("release@actions-go.dev")}
+
+	assert.NoError(t, github.VerifyAssetSignature(ctx, asset, sig, s.cert, trusted...))
+	assert.NoError(t, github.VerifyAssetSignature(ctx, asset, sig, b64Cert, trusted...), "cosign writes base64 encoded certificates")
+
+	err := github.VerifyAssetSignature(ctx, []byte("tampered binary"), sig, s.cert, trusted...)
+	assert.IsType(t, &github.ErrInvalidSignature{}, err)
+	assert.Error(t, github.VerifyAssetSignature(ctx, asset, s.sign(t, []byte("other")), s.cert, trusted...))
+	assert.Error(t, github.VerifyAssetSignature(ctx, asset, sig, s.cert, github.WithCertificateRoots(s.root), github.WithCertificateIdentity("someone@else.dev")))
+	assert.Error(t, github.VerifyAssetSignature(ctx, asset, sig, s.cert, github.WithCertificateRoots(newSigner(t, "release@actions-go.dev").root), github.WithCertificateIdentity("release@actions-go.dev")))
+	assert.Error(t, github.VerifyAssetSignature(ctx, asset, sig, []byte("not a certificate"), trusted...))
+
+	t.Run("certificates are not trusted without roots and identity", func(t *testing.T) {
+		for _, options := range [][]github.AssetSignatureOption{
+			nil,
+			{github.WithCertificateIdentity("release@actions-go.dev")},
+			{github.WithCertificateRoots(s.root)},
+		} {
+			err := github.VerifyAssetSignature(ctx, asset, sig, s.cert, options...)
+			assert.IsType(t, &github.ErrInvalidSignature{}, err)
+		}
+	})
+
+	t.Run("cancelled contexts are reported", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.Equal(t, context.Canceled, github.VerifyAssetSignature(cancelled, asset, sig, s.cert, trusted...))
+	})
+
+	t.Run("public keys can be used instead of certificates", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+		require.NoError(t, err)
+		key := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		assert.NoError(t, github.VerifyAssetSignature(ctx, asset, sig, key))
+		assert.Error(t, github.VerifyAssetSignature(ctx, asset, sig, key, github.WithCertificateRoots(s.root)))
+		assert.Error(t, github.VerifyAssetSignature(ctx, []byte("tampered binary"), sig, key))
+	})
+
+	t.Run("raw signatures are not trimmed", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(&s.key.PublicKey)
+		require.NoError(t, err)
+		key := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		digest := sha256.Sum256(asset)
+		// look for a DER signature ending with a space, as the last byte of S is random
+		for {
+			r, ss, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+			require.NoError(t, err)
+			raw, err := asn1Signature(r, ss)
+			require.NoError(t, err)
+			if raw[len(raw)-1] != ' ' {
+				continue
+			}
+			assert.NoError(t, github.VerifyAssetSignature(ctx, asset, raw, key))
+			assert.NoError(t, github.VerifyAssetSignature(ctx, asset, []byte(base64.StdEncoding.EncodeToString(raw)+"\n"), key))
+			break
+		}
+	})
+}
+
+func asn1Signature(r, s *big.Int) ([]byte, error) {
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const cursorFile = "cursor.json"
+
+func cursorArtifact(name string) string {
+	return "cursor-" + name
+}
+
+// latestArtifact returns the most recent, not expired, artifact named name across all runs of the repository, nil if none
+func latestArtifact(ctx context.Context, name string) (*github.Artifact, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var latest *github.Artifact
+	for {
+		artifacts, resp, err := GitHub.Actions.ListArtifacts(ctx, Context.Repo.Owner, Context.Repo.Repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list artifacts: %v", err)
+		}
+		for _, a := range artifacts.Artifacts {
+			if a.GetName() != name || a.GetExpired() {
+				continue
+			}
+			if latest == nil || a.GetCreatedAt().After(latest.GetCreatedAt().Time) {
+				latest = a
+			}
+		}
+		if resp.NextPage == 0 {
+			return latest, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// SaveCursor stores v, encoded as JSON, in an artifact of the current run so that a later run can resume from it using LoadCursor.
+// This lets scheduled workflows process long lists, issues for example, a page at a time
+func SaveCursor(ctx context.Context, name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cursor %s: %v", name, err)
+	}
+	return UploadArtifact(ctx, cursorArtifact(name), map[string]RepositoryFile{
+		cursorFile: {Path: cursorFile, Data: b},
+	}, nil)
+}
+
+// LoadCursor decodes into v the cursor saved by the most recent run calling SaveCursor with the same name.
+// It returns false, leaving v unchanged, when no cursor is available, for example on the first run or when its artifact expired
+func LoadCursor(ctx context.Context, name string, v interface{}) (bool, error) {
+	artifact, err := latestArtifact(ctx, cursorArtifact(name))
+	if err != nil || artifact == nil {
+		return false, err
+	}
+	archive, err := downloadArtifact(ctx, artifact)
+	if err != nil {
+		return false, err
+	}
+	files, err := readZip(bytes.NewReader(archive), int64(len(archive)), MatchesOneOf("^"+cursorFile+"$"), 0, &DownloadOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to read cursor %s: %v", name, err)
+	}
+	f, ok := files[cursorFile]
+	if !ok {
+		return false, fmt.Errorf("artifact %s does not hold a cursor", artifact.GetName())
+	}
+	if err := json.Unmarshal(f.Data, v); err != nil {
+		return false, fmt.Errorf("failed to decode cursor %s: %v", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,64 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type issueCursor struct {
+	Page   int    `json:"page"`
+	LastID string `json:"last_id"`
+}
+
+func TestCursor(t *testing.T) {
+	service := &artifactService{name: "cursor-issues", failures: map[string]int{}}
+	runtime := httptest.NewServer(service)
+	defer runtime.Close()
+	defer mockEnv(map[string]string{
+		"ACTIONS_RESULTS_URL":   runtime.URL + "/",
+		"ACTIONS_RUNTIME_TOKEN": runtimeToken("run-backend", "job-backend"),
+	})()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	require.NoError(t, SaveCursor(context.Background(), "issues", issueCursor{Page: 3, LastID: "abc"}))
+	assert.Equal(t, 1, service.finalized)
+	require.Contains(t, service.files(t), "cursor.json")
+	archive := service.blob
+
+	artifacts := `[]`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"total_count": 3, "artifacts": %s}`, artifacts)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/artifacts/2/zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", fmt.Sprintf("http://%s/blobs/cursor.zip", r.Host))
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/blobs/cursor.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	defer mockGitHub(mux)()
+
+	cursor := issueCursor{Page: 1}
+	ok, err := LoadCursor(context.Background(), "issues", &cursor)
+	require.NoError(t, err)
+	assert.False(t, ok, "the first run has no cursor")
+	assert.Equal(t, issueCursor{Page: 1}, cursor)
+
+	artifacts = `[
+		{"id": 1, "name": "cursor-issues", "created_at": "2020-06-01T10:00:00Z"},
+		{"id": 2, "name": "cursor-issues", "created_at": "2020-06-02T10:00:00Z"},
+		{"id": 3, "name": "cursor-issues", "created_at": "2020-06-03T10:00:00Z", "expired": true},
+		{"id": 4, "name": "build", "created_at": "2020-06-04T10:00:00Z"}
+	]`
+	ok, err = LoadCursor(context.Background(), "issues", &cursor)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, issueCursor{Page: 3, LastID: "abc"}, cursor)
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+const (
+	// SoftTimeoutInput is the input holding the number of minutes the action is allowed to run.
+	// GitHub does not expose the job timeout, set it to a value lower than the job timeout-minutes
+	SoftTimeoutInput = "soft-timeout-minutes"
+	// DeadlineMargin is the time left to the action to clean up before the soft timeout elapses
+	DeadlineMargin = 30 * time.Second
+)
+
+// softTimeout returns the duration configured in SoftTimeoutInput, ok is false when it is not set or invalid
+func softTimeout() (time.Duration, bool) {
+	value, ok := core.GetInput(SoftTimeoutInput)
+	if !ok || value == "" {
+		return 0, false
+	}
+	minutes, err := strconv.ParseFloat(value, 64)
+	if err != nil || minutes <= 0 {
+		core.Warningf("ignoring invalid %s input %q, it must be a positive number of minutes", SoftTimeoutInput, value)
+		return 0, false
+	}
+	timeout := time.Duration(minutes * float64(time.Minute))
+	if timeout <= DeadlineMargin {
+		// the context would be cancelled right away, leaving no time for the action to run
+		core.Warningf("ignoring %s input %q, it must be longer than the %s left to clean up", SoftTimeoutInput, value, DeadlineMargin)
+		return 0, false
+	}
+	return timeout, true
+}
+
+// WithJobDeadline returns a context cancelled DeadlineMargin before the soft timeout configured in the SoftTimeoutInput input elapses.
+// The timeout starts when WithJobDeadline is called. When the input is not set, or not longer than DeadlineMargin, the context is only cancelled with its parent or by calling cancel.
+// Pass the returned context to API calls and downloads so they abort in time to run cleanup before GitHub kills the job
+func WithJobDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	timeout, ok := softTimeout()
+	if !ok {
+		return ctx, cancel
+	}
+	wait := timeout - DeadlineMargin
+	core.Debugf("cancelling the job context at %s", now().Add(wait).Format(time.RFC3339))
+	fired := after(wait)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-fired:
+			core.Warningf("soft timeout of %s reached, cancelling pending operations", timeout)
+			cancel()
+		}
+	}()
+	return ctx, cancel
+}
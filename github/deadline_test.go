@@ -0,0 +1,62 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithJobDeadline(t *testing.T) {
+	previous := after
+	defer func() { after = previous }()
+	fire := make(chan time.Time, 1)
+	waits := []time.Duration{}
+	after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		return fire
+	}
+	defer setEnv(map[string]string{"INPUT_SOFT-TIMEOUT-MINUTES": "1.5"})()
+
+	ctx, cancel := WithJobDeadline(context.Background())
+	defer cancel()
+	assert.Equal(t, []time.Duration{time.Minute}, waits)
+	assert.NoError(t, ctx.Err())
+
+	fire <- time.Now()
+	select {
+	case <-ctx.Done():
+		assert.Equal(t, context.Canceled, ctx.Err())
+	case <-time.After(time.Second):
+		t.Error("the context was not cancelled at the deadline")
+	}
+
+	t.Run("without a soft timeout the context is not cancelled", func(t *testing.T) {
+		for _, value := range []string{"", "not-a-number", "-5"} {
+			waits = []time.Duration{}
+			defer setEnv(map[string]string{"INPUT_SOFT-TIMEOUT-MINUTES": value})()
+			ctx, cancel := WithJobDeadline(context.Background())
+			assert.Empty(t, waits, value)
+			assert.NoError(t, ctx.Err(), value)
+			cancel()
+			assert.Error(t, ctx.Err(), value)
+		}
+	})
+
+	t.Run("soft timeouts shorter than the margin are ignored", func(t *testing.T) {
+		waits = []time.Duration{}
+		b := bytes.NewBuffer(nil)
+		core.SetStdout(b)
+		defer core.SetStdout(os.Stdout)
+		defer setEnv(map[string]string{"INPUT_SOFT-TIMEOUT-MINUTES": "0.5"})()
+		ctx, cancel := WithJobDeadline(context.Background())
+		defer cancel()
+		assert.Empty(t, waits)
+		assert.NoError(t, ctx.Err())
+		assert.Contains(t, b.String(), "::warning::ignoring soft-timeout-minutes input")
+	})
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	createDiscussionMutation = `mutation($repositoryId: ID!, $categoryId: ID!, $title: String!, $body: String!) {
+  createDiscussion(input: {repositoryId: $repositoryId, categoryId: $categoryId, title: $title, body: $body}) {
+    discussion { id }
+  }
+}`
+	addDiscussionCommentMutation = `mutation($discussionId: ID!, $body: String!) {
+  addDiscussionComment(input: {discussionId: $discussionId, body: $body}) {
+    comment { id }
+  }
+}`
+)
+
+// repositoryNodeID returns the GraphQL identifier of the repository running the workflow, from the payload when available
+func repositoryNodeID(ctx context.Context) (string, error) {
+	if id := Context.Payload.Repository.GetNodeID(); id != "" {
+		return id, nil
+	}
+	repo, _, err := GitHub.Repositories.Get(ctx, Context.Repo.Owner, Context.Repo.Repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository %s/%s: %v", Context.Repo.Owner, Context.Repo.Repo, err)
+	}
+	return repo.GetNodeID(), nil
+}
+
+// CreateDiscussion creates a discussion in the repository running the workflow and returns its GraphQL identifier.
+// categoryID is the GraphQL identifier of the discussion category.
+// The token needs the `discussions: write` permission, an ErrUnauthorized is returned otherwise
+func CreateDiscussion(ctx context.Context, categoryID, title, body string) (string, error) {
+	repositoryID, err := repositoryNodeID(ctx)
+	if err != nil {
+		return "", err
+	}
+	data := struct {
+		CreateDiscussion struct {
+			Discussion struct {
+				ID string `json:"id"`
+			} `json:"discussion"`
+		} `json:"createDiscussion"`
+	}{}
+	err = graphQL(ctx, createDiscussionMutation, map[string]interface{}{
+		"repositoryId": repositoryID,
+		"categoryId":   categoryID,
+		"title":        title,
+		"body":         body,
+	}, &data)
+	if _, ok := err.(*ErrUnauthorized); ok {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create discussion %s: %v", title, err)
+	}
+	return data.CreateDiscussion.Discussion.ID, nil
+}
+
+// CommentOnDiscussion adds a comment to a discussion identified by its GraphQL identifier.
+// The token needs the `discussions: write` permission, an ErrUnauthorized is returned otherwise
+func CommentOnDiscussion(ctx context.Context, discussionID, body string) error {
+	err := graphQL(ctx, addDiscussionCommentMutation, map[string]interface{}{
+		"discussionId": discussionID,
+		"body":         body,
+	}, nil)
+	if _, ok := err.(*ErrUnauthorized); ok {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to comment discussion %s: %v", discussionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLURL(t *testing.T) {
+	previous := GitHub.BaseURL
+	defer func() { GitHub.BaseURL = previous }()
+	GitHub.BaseURL, _ = url.Parse("https://api.github.com/")
+	assert.Equal(t, "https://api.github.com/graphql", graphQLURL())
+	GitHub.BaseURL, _ = url.Parse("https://ghes.example.com/api/v3/")
+	assert.Equal(t, "https://ghes.example.com/api/graphql", graphQLURL())
+}
+
+func TestDiscussions(t *testing.T) {
+	requests := []graphQLRequest{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "toolkit", "node_id": "R_kgDOAAAAAQ"}`)
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		req := graphQLRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		switch {
+		case req.Variables["body"] == "forbidden":
+			fmt.Fprint(w, `{"data": null, "errors": [{"type": "FORBIDDEN", "message": "Resource not accessible by integration"}]}`)
+		case req.Variables["discussionId"] != nil:
+			fmt.Fprint(w, `{"data": {"addDiscussionComment": {"comment": {"id": "DC_kwDOAAAAAc4"}}}}`)
+		default:
+			fmt.Fprint(w, `{"data": {"createDiscussion": {"discussion": {"id": "D_kwDOAAAAAc4"}}}}`)
+		}
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	id, err := CreateDiscussion(context.Background(), "DIC_kwDOAAAAAc4", "Release v1.0.0", "Let's talk about it")
+	require.NoError(t, err)
+	assert.Equal(t, "D_kwDOAAAAAc4", id)
+	if assert.Len(t, requests, 1) {
+		assert.Contains(t, requests[0].Query, "createDiscussion")
+		assert.Equal(t, map[string]interface{}{
+			"repositoryId": "R_kgDOAAAAAQ",
+			"categoryId":   "DIC_kwDOAAAAAc4",
+			"title":        "Release v1.0.0",
+			"body":         "Let's talk about it",
+		}, requests[0].Variables)
+	}
+
+	require.NoError(t, CommentOnDiscussion(context.Background(), id, "Released!"))
+	if assert.Len(t, requests, 2) {
+		assert.Contains(t, requests[1].Query, "addDiscussionComment")
+		assert.Equal(t, "D_kwDOAAAAAc4", requests[1].Variables["discussionId"])
+	}
+
+	t.Run("permission errors are typed", func(t *testing.T) {
+		err := CommentOnDiscussion(context.Background(), id, "forbidden")
+		if assert.IsType(t, &ErrUnauthorized{}, err) {
+			assert.Contains(t, err.Error(), "Resource not accessible by integration")
+		}
+		_, err = CreateDiscussion(context.Background(), "DIC_kwDOAAAAAc4", "Release v1.0.0", "forbidden")
+		assert.IsType(t, &ErrUnauthorized{}, err)
+	})
+}
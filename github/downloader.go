@@ -0,0 +1,61 @@
+package github
+
+import (
+	"net/http"
+	"sync"
+)
+
+type cachedTarball struct {
+	etag  string
+	files map[string]RepositoryFile
+}
+
+// CachedDownloader downloads repository files, reusing the files of the previous download of a branch while its tarball
+// keeps the same ETag. Conditional requests answered with 304 Not Modified do not count against the rate limit.
+// ETags and files are only kept in memory: the cache only helps within a single process, typically an action
+// downloading the same branch several times, and every run starts with a full download
+type CachedDownloader struct {
+	client *http.Client
+	lock   sync.Mutex
+	cache  map[string]cachedTarball
+}
+
+// NewCachedDownloader returns a downloader using c, HTTPClient when c is nil
+func NewCachedDownloader(c *http.Client) *CachedDownloader {
+	return &CachedDownloader{
+		client: c,
+		cache:  map[string]cachedTarball{},
+	}
+}
+
+// Download returns the files of a repository branch matching include, see DownloadRepositoryFiles.
+// modified is false when the files are the ones of the previous download.
+// All files are cached, so that the same downloader can be used with different matchers
+func (d *CachedDownloader) Download(owner, repo, branch string, include Matcher, options *DownloadOptions) (files map[string]RepositoryFile, modified bool, err error) {
+	key := owner + "/" + repo + "@" + branch
+	d.lock.Lock()
+	cached, ok := d.cache[key]
+	d.lock.Unlock()
+
+	all, etag, err := DownloadRepositoryFilesIfModified(d.client, owner, repo, branch, func(string) bool { return true }, cached.etag, options)
+	switch {
+	case err == ErrNotModified && ok:
+		all, modified = cached.files, false
+	case err != nil:
+		return nil, false, err
+	default:
+		modified = true
+		if etag != "" {
+			d.lock.Lock()
+			d.cache[key] = cachedTarball{etag: etag, files: all}
+			d.lock.Unlock()
+		}
+	}
+	files = map[string]RepositoryFile{}
+	for path, f := range all {
+		if include(path) {
+			files[path] = f
+		}
+	}
+	return files, modified, nil
+}
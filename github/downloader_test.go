@@ -0,0 +1,61 @@
+package github_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedDownloader(t *testing.T) {
+	archive := bytes.NewBuffer(nil)
+	require.NoError(t, github.WriteTarGz(archive, map[string]github.RepositoryFile{
+		"actions-go-toolkit-d74fd51/go.mod":       {Data: []byte("module github.com/actions-go/toolkit")},
+		"actions-go-toolkit-d74fd51/core/core.go": {Data: []byte("package core")},
+	}))
+	downloads, notModified := 0, 0
+	c := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/repos/actions-go/toolkit/tarball/main", r.URL.Path)
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: r, Body: ioutil.NopCloser(bytes.NewReader(nil))}
+		resp.Header.Set("ETag", `"d74fd51"`)
+		if r.Header.Get("If-None-Match") == `"d74fd51"` {
+			notModified++
+			resp.StatusCode = http.StatusNotModified
+			return resp, nil
+		}
+		downloads++
+		resp.Header.Set("Content-Type", "application/gzip")
+		resp.Body = ioutil.NopCloser(bytes.NewReader(archive.Bytes()))
+		return resp, nil
+	})}
+
+	d := github.NewCachedDownloader(c)
+	files, modified, err := d.Download("actions-go", "toolkit", "main", github.MatchesOneOf("\\.go$"), nil)
+	require.NoError(t, err)
+	assert.True(t, modified)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "package core", string(files["core/core.go"].Data))
+
+	files, modified, err = d.Download("actions-go", "toolkit", "main", github.MatchesOneOf(".*"), nil)
+	require.NoError(t, err)
+	assert.False(t, modified)
+	assert.Len(t, files, 2, "cached files are filtered again")
+	assert.Equal(t, 1, downloads)
+	assert.Equal(t, 1, notModified)
+
+	t.Run("conditional downloads return ErrNotModified", func(t *testing.T) {
+		files, etag, err := github.DownloadRepositoryFilesIfModified(c, "actions-go", "toolkit", "main", github.MatchesOneOf(".*"), `"d74fd51"`, nil)
+		assert.Equal(t, github.ErrNotModified, err)
+		assert.Nil(t, files)
+		assert.Equal(t, `"d74fd51"`, etag)
+
+		files, etag, err = github.DownloadRepositoryFilesIfModified(c, "actions-go", "toolkit", "main", github.MatchesOneOf(".*"), `"outdated"`, nil)
+		assert.NoError(t, err)
+		assert.Len(t, files, 2)
+		assert.Equal(t, `"d74fd51"`, etag)
+	})
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// getenv reads the environment, it is replaced in tests to avoid altering the process environment
+var getenv = os.Getenv
+
+func withDefault(v, dflt string) string {
+	if v == "" {
+		return dflt
+	}
+	return v
+}
+
+func githubEnv(name string) string {
+	return getenv("GITHUB_" + name)
+}
+
+func githubEnvNumber(name string) int64 {
+	v := githubEnv(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		core.Warningf("unable to parse GITHUB_%s=%s as a number: %v", name, v, err)
+		return 0
+	}
+	return n
+}
+
+// ServerURL returns the URL of the GitHub server running the workflow, for example https://github.com
+func ServerURL() string {
+	return strings.TrimSuffix(withDefault(githubEnv("SERVER_URL"), "https://github.com"), "/")
+}
+
+// Repository returns the owner and repository name running the workflow, for example actions-go/toolkit
+func Repository() string {
+	return githubEnv("REPOSITORY")
+}
+
+// RunID returns the unique identifier of the current workflow run, 0 when not available
+func RunID() int64 {
+	return githubEnvNumber("RUN_ID")
+}
+
+// Job returns the identifier of the current job, as declared in the workflow
+func Job() string {
+	return githubEnv("JOB")
+}
+
+// Workspace returns the directory holding the repository checkout, the current directory when not available
+func Workspace() string {
+	return withDefault(githubEnv("WORKSPACE"), ".")
+}
+
+// RunAttempt returns the attempt number of the current workflow run, starting at 1 and incremented on each re-run.
+// 1 is returned when not available
+func RunAttempt() int64 {
+	if attempt := githubEnvNumber("RUN_ATTEMPT"); attempt > 0 {
+		return attempt
+	}
+	return 1
+}
+
+// IsRerun returns whether the current workflow run is a re-run of a previous attempt
+func IsRerun() bool {
+	return RunAttempt() > 1
+}
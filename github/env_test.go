@@ -0,0 +1,29 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvAccessors(t *testing.T) {
+	defer mockEnv(map[string]string{
+		"GITHUB_SERVER_URL":  "https://github.example.com/",
+		"GITHUB_REPOSITORY":  "actions-go/toolkit",
+		"GITHUB_RUN_ID":      "1234",
+		"GITHUB_RUN_ATTEMPT": "not-a-number",
+		"GITHUB_JOB":         "build",
+	})()
+	assert.Equal(t, "https://github.example.com", ServerURL())
+	assert.Equal(t, "actions-go/toolkit", Repository())
+	assert.EqualValues(t, 1234, RunID())
+	assert.EqualValues(t, 1, RunAttempt(), "invalid numbers are ignored")
+	assert.Equal(t, "build", Job())
+	assert.Equal(t, ".", Workspace())
+
+	t.Run("defaults apply to missing variables", func(t *testing.T) {
+		defer mockEnv(map[string]string{})()
+		assert.Equal(t, "https://github.com", ServerURL())
+		assert.EqualValues(t, 0, RunID())
+	})
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	// ProtectionRuleRequiredReviewers is the type of rules requiring a review before deploying
+	ProtectionRuleRequiredReviewers = "required_reviewers"
+	// ProtectionRuleWaitTimer is the type of rules delaying deployments
+	ProtectionRuleWaitTimer = "wait_timer"
+	// ProtectionRuleBranchPolicy is the type of rules restricting the branches that can be deployed
+	ProtectionRuleBranchPolicy = "branch_policy"
+	// ProtectionRuleCustom is the type of rules enforced by a GitHub App, see DeploymentCallbackURL
+	ProtectionRuleCustom = "custom"
+)
+
+// EnvironmentReviewer is a user or team allowed to approve deployments to an environment
+type EnvironmentReviewer struct {
+	// Type is either User or Team
+	Type     string       `json:"type"`
+	Reviewer *github.User `json:"reviewer"`
+}
+
+// ProtectionRule is a rule a deployment to an environment must satisfy
+type ProtectionRule struct {
+	ID int64 `json:"id"`
+	// Type is one of the ProtectionRule constants
+	Type string `json:"type"`
+	// WaitTimer is the number of minutes deployments are delayed by for wait_timer rules
+	WaitTimer         int                   `json:"wait_timer,omitempty"`
+	PreventSelfReview bool                  `json:"prevent_self_review,omitempty"`
+	Reviewers         []EnvironmentReviewer `json:"reviewers,omitempty"`
+	// App is the GitHub App enforcing custom rules
+	App     *github.App `json:"app,omitempty"`
+	Enabled bool        `json:"enabled,omitempty"`
+}
+
+// Wait returns the delay a wait_timer rule imposes to deployments, 0 for other rules
+func (r ProtectionRule) Wait() time.Duration {
+	return time.Duration(r.WaitTimer) * time.Minute
+}
+
+// DeploymentEnvironment is a deployment environment of a repository
+type DeploymentEnvironment struct {
+	ID              int64            `json:"id"`
+	Name            string           `json:"name"`
+	HTMLURL         string           `json:"html_url"`
+	ProtectionRules []ProtectionRule `json:"protection_rules"`
+}
+
+// Environment returns the environment called name of the repository running the workflow.
+// An ErrNotFound is returned when it does not exist
+func Environment(ctx context.Context, name string) (*DeploymentEnvironment, error) {
+	u := fmt.Sprintf("repos/%s/%s/environments/%s", Context.Repo.Owner, Context.Repo.Repo, url.PathEscape(name))
+	req, err := GitHub.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	env := &DeploymentEnvironment{}
+	resp, err := GitHub.Do(ctx, req, env)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrNotFound{Resource: "environment " + name, Err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment %s: %v", name, PermissionError(resp, err))
+	}
+	return env, nil
+}
+
+type customProtectionRules struct {
+	Rules []ProtectionRule `json:"custom_deployment_protection_rules"`
+}
+
+// DeploymentProtectionRules returns the rules deployments to the environment called name must satisfy:
+// required reviewers, wait timers, branch policies and the custom rules enforced by GitHub Apps
+func DeploymentProtectionRules(ctx context.Context, name string) ([]ProtectionRule, error) {
+	env, err := Environment(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	rules := []ProtectionRule{}
+	for _, r := range env.ProtectionRules {
+		// custom rules are only partially described in the environment, they are listed below
+		if r.Type != ProtectionRuleCustom {
+			rules = append(rules, r)
+		}
+	}
+	u := fmt.Sprintf("repos/%s/%s/environments/%s/deployment_protection_rules", Context.Repo.Owner, Context.Repo.Repo, url.PathEscape(name))
+	req, err := GitHub.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	custom := &customProtectionRules{}
+	resp, err := GitHub.Do(ctx, req, custom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom protection rules of environment %s: %v", name, PermissionError(resp, err))
+	}
+	for _, r := range custom.Rules {
+		r.Type = ProtectionRuleCustom
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// DeploymentCallbackURL returns the URL a GitHub App enforcing a custom protection rule reviews the deployment with,
+// for deployment_protection_rule events, empty for other events
+func DeploymentCallbackURL() string {
+	return Context.Payload.DeploymentCallbackURL
+}
+
+// ReviewDeployment approves or rejects the deployment that triggered a deployment_protection_rule event.
+// state is either approved or rejected. The token must be the one of the GitHub App enforcing the rule
+func ReviewDeployment(ctx context.Context, state, comment string) error {
+	if state != "approved" && state != "rejected" {
+		return fmt.Errorf("invalid review state %s, it must be either approved or rejected", state)
+	}
+	callback := DeploymentCallbackURL()
+	if callback == "" {
+		return fmt.Errorf("the workflow has not been triggered by a deployment_protection_rule event")
+	}
+	review := map[string]string{
+		"environment_name": Context.Payload.Environment,
+		"state":            state,
+		"comment":          comment,
+	}
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		req, err := GitHub.NewRequest(http.MethodPost, callback, review)
+		if err != nil {
+			return nil, err
+		}
+		return GitHub.Do(ctx, req, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to review the deployment to %s: %v", Context.Payload.Environment, err)
+	}
+	return nil
+}
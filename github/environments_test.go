@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentProtectionRules(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/environments/production", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 161088068, "name": "production", "html_url": "https://github.com/actions-go/toolkit/deployments/activity_log?environments_filter=production",
+			"protection_rules": [
+				{"id": 3736, "type": "wait_timer", "wait_timer": 30},
+				{"id": 3755, "type": "required_reviewers", "prevent_self_review": true, "reviewers": [{"type": "User", "reviewer": {"login": "tjamet"}}, {"type": "Team", "reviewer": {"id": 1}}]},
+				{"id": 3756, "type": "branch_policy"},
+				{"id": 3757, "type": "custom"}
+			]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/environments/production/deployment_protection_rules", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 1, "custom_deployment_protection_rules": [{"id": 3757, "enabled": true, "app": {"id": 1, "slug": "deploy-gate"}}]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/environments/staging", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	env, err := Environment(context.Background(), "production")
+	require.NoError(t, err)
+	assert.Equal(t, "production", env.Name)
+	assert.Len(t, env.ProtectionRules, 4)
+
+	rules, err := DeploymentProtectionRules(context.Background(), "production")
+	require.NoError(t, err)
+	if assert.Len(t, rules, 4) {
+		assert.Equal(t, ProtectionRuleWaitTimer, rules[0].Type)
+		assert.Equal(t, 30*time.Minute, rules[0].Wait())
+		assert.Equal(t, ProtectionRuleRequiredReviewers, rules[1].Type)
+		assert.True(t, rules[1].PreventSelfReview)
+		if assert.Len(t, rules[1].Reviewers, 2) {
+			assert.Equal(t, "tjamet", rules[1].Reviewers[0].Reviewer.GetLogin())
+		}
+		assert.Equal(t, ProtectionRuleBranchPolicy, rules[2].Type)
+		assert.Equal(t, ProtectionRuleCustom, rules[3].Type)
+		assert.Equal(t, "deploy-gate", rules[3].App.GetSlug())
+		assert.True(t, rules[3].Enabled)
+	}
+
+	_, err = Environment(context.Background(), "staging")
+	assert.IsType(t, &ErrNotFound{}, err)
+}
+
+func TestReviewDeployment(t *testing.T) {
+	defer setEnv(map[string]string{"GITHUB_EVENT_NAME": "deployment_protection_rule", "GITHUB_EVENT_PATH": "deployment_protection_rule_event.json"})()
+	defer mockContext(ParseActionEnv())()
+	assert.Equal(t, "https://api.github.com/repos/actions-go/toolkit/actions/runs/30433642/deployment_protection_rule", DeploymentCallbackURL())
+	assert.Equal(t, "production", Context.Payload.Environment)
+	assert.Equal(t, int64(145988746), Context.Payload.Deployment.GetID())
+
+	reviews := []map[string]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/30433642/deployment_protection_rule", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		review := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		reviews = append(reviews, review)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer mockGitHub(mux)()
+	Context.Payload.DeploymentCallbackURL = GitHub.BaseURL.String() + "repos/actions-go/toolkit/actions/runs/30433642/deployment_protection_rule"
+
+	require.NoError(t, ReviewDeployment(context.Background(), "approved", "all checks passed"))
+	assert.Equal(t, []map[string]string{{"environment_name": "production", "state": "approved", "comment": "all checks passed"}}, reviews)
+	assert.Error(t, ReviewDeployment(context.Background(), "maybe", ""))
+
+	t.Run("other events can't be reviewed", func(t *testing.T) {
+		Context = ActionContext{}
+		assert.Equal(t, "", DeploymentCallbackURL())
+		assert.Error(t, ReviewDeployment(context.Background(), "rejected", ""))
+	})
+}
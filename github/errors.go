@@ -0,0 +1,203 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotModified is returned by conditional downloads when the content did not change since the previous download
+var ErrNotModified = errors.New("not modified")
+
+// MultiError gathers errors that did not interrupt an operation
+type MultiError []error
+
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e MultiError) errorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ErrRateLimited is returned when a call is rejected by GitHub rate limits
+type ErrRateLimited struct {
+	// RetryAfter is the delay after which the call may be retried
+	RetryAfter time.Duration
+	// Secondary reports whether the secondary, abuse detection, rate limit has been hit
+	Secondary bool
+	Err       error
+}
+
+func (e *ErrRateLimited) Error() string {
+	kind := "primary"
+	if e.Secondary {
+		kind = "secondary"
+	}
+	return fmt.Sprintf("%s rate limit exceeded, retry after %v: %v", kind, e.RetryAfter, e.Err)
+}
+
+// ErrSearchTruncated is returned when a search matches more results than the search API returns
+type ErrSearchTruncated struct {
+	Query string
+	// Total is the number of results matching the query
+	Total int
+}
+
+func (e *ErrSearchTruncated) Error() string {
+	return fmt.Sprintf("search %s matched %d results, only the first %d are available", e.Query, e.Total, searchResultsCap)
+}
+
+// ErrUnauthorized is returned when a call is rejected because the token lacks permissions
+type ErrUnauthorized struct {
+	// RequiredPermissions lists the permissions GitHub accepts for the call, for example contents: write
+	RequiredPermissions []string
+	Err                 error
+}
+
+func (e *ErrUnauthorized) Error() string {
+	if len(e.RequiredPermissions) == 0 {
+		return fmt.Sprintf("token is not authorized: %v", e.Err)
+	}
+	return fmt.Sprintf("token needs `%s`: %v", strings.Join(e.RequiredPermissions, ", "), e.Err)
+}
+
+// ErrNotFound is returned when a requested resource does not exist or is not visible with the current token
+type ErrNotFound struct {
+	// Resource describes what was requested, for example gist 1234
+	Resource string
+	Err      error
+}
+
+func (e *ErrNotFound) Error() string {
+	return fmt.Sprintf("%s not found: %v", e.Resource, e.Err)
+}
+
+// ErrTreeTruncated is returned when a repository tree has more entries than the trees API returns.
+// Download the repository tarball to list all files instead
+type ErrTreeTruncated struct {
+	Owner string
+	Repo  string
+	Ref   string
+}
+
+func (e *ErrTreeTruncated) Error() string {
+	return fmt.Sprintf("tree of %s/%s at %s is truncated", e.Owner, e.Repo, e.Ref)
+}
+
+// ErrFilesTruncated is returned when commits change more files than the API lists.
+// Compare a clone of the repository to list all files instead
+type ErrFilesTruncated struct {
+	// Comparison describes the changes listed, for example abc...def
+	Comparison string
+	// Limit is the number of files the API lists
+	Limit int
+}
+
+func (e *ErrFilesTruncated) Error() string {
+	return fmt.Sprintf("%s changes more than the %d files the API lists", e.Comparison, e.Limit)
+}
+
+// ErrInvalidSignature is returned when the signature of a webhook payload or of a release asset does not match it
+type ErrInvalidSignature struct {
+	// Reason describes why the signature has been rejected
+	Reason string
+}
+
+func (e *ErrInvalidSignature) Error() string {
+	return fmt.Sprintf("invalid payload signature: %s", e.Reason)
+}
+
+// ErrNotMergeable is returned when GitHub refuses to merge a pull request, for example because required checks failed
+type ErrNotMergeable struct {
+	Number int
+	Err    error
+}
+
+func (e *ErrNotMergeable) Error() string {
+	return fmt.Sprintf("pull request %d is not mergeable: %v", e.Number, e.Err)
+}
+
+// ErrHeadChanged is returned when a pull request head is not the expected commit anymore, the merge can be retried after reviewing the new head
+type ErrHeadChanged struct {
+	Number int
+	// ExpectedSHA is the head commit the merge was requested for
+	ExpectedSHA string
+	Err         error
+}
+
+func (e *ErrHeadChanged) Error() string {
+	return fmt.Sprintf("head of pull request %d is not %s anymore: %v", e.Number, e.ExpectedSHA, e.Err)
+}
+
+// ErrBranchMoved is returned when a branch has been updated while a commit was being prepared on top of its previous head.
+// The commit can be prepared again from the new head
+type ErrBranchMoved struct {
+	Branch string
+	// ExpectedSHA is the head the commit has been prepared on
+	ExpectedSHA string
+	Err         error
+}
+
+func (e *ErrBranchMoved) Error() string {
+	return fmt.Sprintf("branch %s moved from %s: %v", e.Branch, e.ExpectedSHA, e.Err)
+}
+
+// ErrSizeLimitExceeded is returned when extracted files exceed the MaxFileBytes or MaxTotalBytes download options
+type ErrSizeLimitExceeded struct {
+	// Path is the archive entry that exceeded the limit
+	Path  string
+	Limit int64
+	// Total reports whether the limit is the one on all files, MaxTotalBytes
+	Total bool
+}
+
+func (e *ErrSizeLimitExceeded) Error() string {
+	if e.Total {
+		return fmt.Sprintf("extracting %s exceeds the total limit of %d bytes", e.Path, e.Limit)
+	}
+	return fmt.Sprintf("%s exceeds the file limit of %d bytes", e.Path, e.Limit)
+}
+
+// ErrDecompressionBomb is returned when an archive inflates beyond the MaxUncompressedBytes or MaxDecompressionRatio download options
+type ErrDecompressionBomb struct {
+	// Path is the archive entry being inflated, empty when the whole archive is compressed, like gzipped tarballs
+	Path         string
+	Compressed   int64
+	Uncompressed int64
+}
+
+func (e *ErrDecompressionBomb) Error() string {
+	what := "archive"
+	if e.Path != "" {
+		what = e.Path
+	}
+	return fmt.Sprintf("%s inflates from %d to more than %d bytes, it may be a decompression bomb", what, e.Compressed, e.Uncompressed)
+}
+
+// ErrMissingArtifactFiles is returned when a downloaded artifact does not hold the expected files, see DownloadArtifactExpecting
+type ErrMissingArtifactFiles struct {
+	Artifact string
+	Missing  []string
+	// Unexpected are the files not expected, only reported when rejecting extra files with WithoutExtraFiles
+	Unexpected []string
+}
+
+func (e *ErrMissingArtifactFiles) Error() string {
+	problems := []string{}
+	if len(e.Missing) > 0 {
+		problems = append(problems, fmt.Sprintf("is missing %s", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Unexpected) > 0 {
+		problems = append(problems, fmt.Sprintf("has unexpected %s", strings.Join(e.Unexpected, ", ")))
+	}
+	return fmt.Sprintf("artifact %s %s", e.Artifact, strings.Join(problems, " and "))
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// gistFileContent returns the full content of a gist file, downloading it when the API truncated it
+func gistFileContent(ctx context.Context, f github.GistFile) ([]byte, error) {
+	content := f.GetContent()
+	if len(content) >= f.GetSize() || f.GetRawURL() == "" {
+		return []byte(content), nil
+	}
+	req, err := GitHub.NewRequest(http.MethodGet, f.GetRawURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	b := bytes.NewBuffer(nil)
+	if _, err := GitHub.Do(ctx, req, b); err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", f.GetFilename(), err)
+	}
+	return b.Bytes(), nil
+}
+
+// DownloadGist returns the files of a gist keyed by file name. Private gists can be downloaded when the token allows it.
+// An ErrNotFound is returned when the gist does not exist
+func DownloadGist(ctx context.Context, gistID string) (map[string]RepositoryFile, error) {
+	gist, resp, err := GitHub.Gists.Get(ctx, gistID)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, &ErrNotFound{Resource: "gist " + gistID, Err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gist %s: %v", gistID, err)
+	}
+	files := map[string]RepositoryFile{}
+	for name, f := range gist.Files {
+		data, err := gistFileContent(ctx, f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download gist %s: %v", gistID, err)
+		}
+		hdr := &tar.Header{Name: string(name), Mode: 0644, Size: int64(len(data)), ModTime: gist.GetUpdatedAt(), Typeflag: tar.TypeReg}
+		files[string(name)] = RepositoryFile{Path: string(name), FileInfo: hdr.FileInfo(), Data: data}
+	}
+	return files, nil
+}
@@ -0,0 +1,41 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadGist(t *testing.T) {
+	large := strings.Repeat("x", 2048)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gists/abc123", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": "abc123", "updated_at": "2020-06-01T10:00:00Z", "files": {
+			"config.yml": {"filename": "config.yml", "size": 13, "content": "label: stale\n"},
+			"large.txt": {"filename": "large.txt", "size": 2048, "truncated": true, "content": "xxxx", "raw_url": "http://%s/raw/abc123/large.txt"}
+		}}`, r.Host)
+	})
+	mux.HandleFunc("/raw/abc123/large.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, large)
+	})
+	mux.HandleFunc("/gists/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	defer mockGitHub(mux)()
+
+	files, err := DownloadGist(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "label: stale\n", string(files["config.yml"].Data))
+	assert.Equal(t, large, string(files["large.txt"].Data), "truncated files must be downloaded from their raw URL")
+	assert.EqualValues(t, 2048, files["large.txt"].FileInfo.Size())
+
+	_, err = DownloadGist(context.Background(), "missing")
+	assert.IsType(t, &ErrNotFound{}, err)
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// CommitOption customises commits created by CommitFiles
+type CommitOption func(*commitOptions)
+
+type commitOptions struct {
+	author    *github.CommitAuthor
+	committer *github.CommitAuthor
+}
+
+// WithCommitAuthor sets the author of the commit, it defaults to the owner of the token
+func WithCommitAuthor(name, email string) CommitOption {
+	return func(o *commitOptions) {
+		o.author = &github.CommitAuthor{Name: github.String(name), Email: github.String(email)}
+	}
+}
+
+// WithCommitCommitter sets the committer of the commit, it defaults to the author
+func WithCommitCommitter(name, email string) CommitOption {
+	return func(o *commitOptions) {
+		o.committer = &github.CommitAuthor{Name: github.String(name), Email: github.String(email)}
+	}
+}
+
+func treeEntries(ctx context.Context, owner, repo string, files map[string][]byte, deletions []string) ([]*github.TreeEntry, error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	entries := make([]*github.TreeEntry, 0, len(files)+len(deletions))
+	for _, p := range paths {
+		var blob *github.Blob
+		err := RetryRateLimited(ctx, func() (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			blob, resp, err = GitHub.Git.CreateBlob(ctx, owner, repo, &github.Blob{
+				Content:  github.String(base64.StdEncoding.EncodeToString(files[p])),
+				Encoding: github.String("base64"),
+			})
+			return resp, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create blob for %s: %v", p, err)
+		}
+		entries = append(entries, &github.TreeEntry{Path: github.String(p), Mode: github.String("100644"), Type: github.String("blob"), SHA: blob.SHA})
+	}
+	for _, p := range deletions {
+		// entries without SHA nor content are removed from the base tree
+		entries = append(entries, &github.TreeEntry{Path: github.String(p), Mode: github.String("100644"), Type: github.String("blob")})
+	}
+	return entries, nil
+}
+
+// CommitFiles writes files and removes deletions from branch in a single commit using the git data API, and returns its SHA.
+// The commit is created on top of the current head of the branch. When the branch moves before it is updated,
+// an ErrBranchMoved is returned and the branch is left untouched
+func CommitFiles(ctx context.Context, owner, repo, branch string, files map[string][]byte, deletions []string, message string, options ...CommitOption) (string, error) {
+	o := commitOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+	ref, _, err := GitHub.Git.GetRef(ctx, owner, repo, "heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get head of %s: %v", branch, err)
+	}
+	head := ref.GetObject().GetSHA()
+	parent, _, err := GitHub.Git.GetCommit(ctx, owner, repo, head)
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %v", head, err)
+	}
+	entries, err := treeEntries(ctx, owner, repo, files, deletions)
+	if err != nil {
+		return "", err
+	}
+	var tree *github.Tree
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		tree, resp, err = GitHub.Git.CreateTree(ctx, owner, repo, parent.GetTree().GetSHA(), entries)
+		return resp, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %v", err)
+	}
+	var commit *github.Commit
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		commit, resp, err = GitHub.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+			Message:   github.String(message),
+			Tree:      &github.Tree{SHA: tree.SHA},
+			Parents:   []*github.Commit{{SHA: github.String(head)}},
+			Author:    o.author,
+			Committer: o.committer,
+		})
+		return resp, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %v", err)
+	}
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		_, resp, err := GitHub.Git.UpdateRef(ctx, owner, repo, &github.Reference{
+			Ref:    github.String("refs/heads/" + branch),
+			Object: &github.GitObject{SHA: commit.SHA},
+		}, false)
+		if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+			return resp, &ErrBranchMoved{Branch: branch, ExpectedSHA: head, Err: err}
+		}
+		return resp, err
+	})
+	if e, ok := err.(*ErrBranchMoved); ok {
+		return "", e
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %v", branch, err)
+	}
+	return commit.GetSHA(), nil
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitFiles(t *testing.T) {
+	calls := []string{}
+	moved := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "GET ref")
+		fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "head-sha"}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/refs/heads/main", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" ref")
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{"sha": "commit-sha", "force": false}, body)
+		if moved {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Update is not a fast forward"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"sha": "commit-sha"}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/commits/head-sha", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "GET commit")
+		fmt.Fprint(w, `{"sha": "head-sha", "tree": {"sha": "base-tree"}}`)
+	})
+	blobs := 0
+	mux.HandleFunc("/repos/actions-go/toolkit/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "POST blob")
+		blobs++
+		fmt.Fprintf(w, `{"sha": "blob-%d"}`, blobs)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "POST tree")
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"base_tree": "base-tree", "tree": [
+			{"path": "README.md", "mode": "100644", "type": "blob", "sha": "blob-1"},
+			{"path": "gen/code.go", "mode": "100644", "type": "blob", "sha": "blob-2"},
+			{"path": "gen/old.go", "mode": "100644", "type": "blob", "sha": null}
+		]}`, string(b))
+		fmt.Fprint(w, `{"sha": "new-tree"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "POST commit")
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"message": "Regenerate code",
+			"tree": "new-tree",
+			"parents": ["head-sha"],
+			"author": {"name": "bot", "email": "bot@actions-go.dev"}
+		}`, string(b))
+		fmt.Fprint(w, `{"sha": "commit-sha"}`)
+	})
+	defer mockGitHub(mux)()
+
+	files := map[string][]byte{"gen/code.go": []byte("package gen"), "README.md": []byte("# toolkit")}
+	sha, err := CommitFiles(context.Background(), "actions-go", "toolkit", "main", files, []string{"gen/old.go"}, "Regenerate code", WithCommitAuthor("bot", "bot@actions-go.dev"))
+	require.NoError(t, err)
+	assert.Equal(t, "commit-sha", sha)
+	assert.Equal(t, []string{"GET ref", "GET commit", "POST blob", "POST blob", "POST tree", "POST commit", "PATCH ref"}, calls)
+
+	t.Run("branches moved in the meantime are not overwritten", func(t *testing.T) {
+		moved, blobs = true, 0
+		_, err := CommitFiles(context.Background(), "actions-go", "toolkit", "main", files, []string{"gen/old.go"}, "Regenerate code", WithCommitAuthor("bot", "bot@actions-go.dev"))
+		if assert.IsType(t, &ErrBranchMoved{}, err) {
+			assert.Equal(t, "head-sha", err.(*ErrBranchMoved).ExpectedSHA)
+		}
+	})
+}
@@ -1,42 +1,145 @@
 package github
 
 import (
-	"archive/tar"
-	"bytes"
-	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"regexp"
-	"strings"
 
 	"github.com/actions-go/toolkit/core"
 	"github.com/google/go-github/v32/github"
 	"golang.org/x/oauth2"
 )
 
+// token returns the installation token registered by UseAppAuth, or the default token:
+// the GITHUB_TOKEN environment variable, then the github-token and token inputs
 func token() string {
-	if t := os.Getenv("GITHUB_TOKEN"); t != "" {
-		return t
-	}
-	for _, input := range []string{"github-token", "token"} {
-		if t, ok := core.GetInput(input); ok {
+	if s := currentAppAuth(); s != nil {
+		if t, err := s.installationToken(context.Background()); err == nil {
 			return t
 		}
 	}
-	return ""
+	t, _ := TokenFrom(EnvToken, InputToken("github-token"), InputToken("token"))
+	return t
+}
+
+// ClientOption customises the client returned by NewClient
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	conditionalRequests bool
+	caCerts             [][]byte
+	insecureSkipVerify  bool
+}
+
+// WithConditionalRequests enables caching GET responses in memory along with their ETag.
+// Subsequent identical requests are sent with If-None-Match and the cached response is returned
+// when GitHub answers 304 Not Modified, which does not count against the rate limit.
+func WithConditionalRequests() ClientOption {
+	return func(o *clientOptions) {
+		o.conditionalRequests = true
+	}
+}
+
+// WithCACert trusts the PEM encoded certificates in addition to the system ones,
+// typically to reach a GitHub Enterprise server using an internal certificate authority
+func WithCACert(pemBytes []byte) ClientOption {
+	return func(o *clientOptions) {
+		o.caCerts = append(o.caCerts, pemBytes)
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the server certificate.
+// This exposes the token to anyone able to intercept the traffic and should only be used as a last resort
+func WithInsecureSkipVerify(insecure bool) ClientOption {
+	return func(o *clientOptions) {
+		o.insecureSkipVerify = insecure
+	}
+}
+
+func (o clientOptions) tlsConfigured() bool {
+	return len(o.caCerts) > 0 || o.insecureSkipVerify
+}
+
+// tlsTransport returns a copy of base using the configured certificate authorities
+func (o clientOptions) tlsTransport(base http.RoundTripper) http.RoundTripper {
+	t, ok := base.(*http.Transport)
+	if !ok {
+		core.Warningf("unable to configure TLS on a %T transport", base)
+		return base
+	}
+	t = t.Clone()
+	config := &tls.Config{}
+	if t.TLSClientConfig != nil {
+		config = t.TLSClientConfig.Clone()
+	}
+	if len(o.caCerts) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for _, pem := range o.caCerts {
+			if !pool.AppendCertsFromPEM(pem) {
+				core.Warning("unable to parse the provided CA certificate, it is ignored")
+			}
+		}
+		config.RootCAs = pool
+	}
+	if o.insecureSkipVerify {
+		core.Warning("TLS certificate verification is disabled, connections to GitHub are not secure")
+		config.InsecureSkipVerify = true
+	}
+	t.TLSClientConfig = config
+	return t
+}
+
+// HTTPClient, when set, is the base client for API and download requests, for example to trust the CA of a GitHub Enterprise server.
+// The token is injected on top of its transport. As GitHub is created at startup, it must be re-created using NewClient
+// after HTTPClient is changed
+var HTTPClient *http.Client
+
+func baseHTTPClient() *http.Client {
+	if HTTPClient != nil {
+		return HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// NewHTTPClient returns an unauthenticated client built on HTTPClient with the provided options applied,
+// typically to download repository files from a GitHub Enterprise server
+func NewHTTPClient(options ...ClientOption) *http.Client {
+	o := clientOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+	base := baseHTTPClient()
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if o.tlsConfigured() {
+		transport = o.tlsTransport(transport)
+	}
+	if o.conditionalRequests {
+		transport = newConditionalTransport(transport)
+	}
+	httpClient := *base
+	httpClient.Transport = transport
+	return &httpClient
 }
 
-func NewClient() *github.Client {
+// NewClient returns a GitHub client, authenticated with the action token when available
+func NewClient(options ...ClientOption) *github.Client {
+	httpClient := NewHTTPClient(options...)
 	token := token()
-	httpClient := http.DefaultClient
 	if token != "" {
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: token},
 		)
-		httpClient = oauth2.NewClient(context.Background(), ts)
+		httpClient.Transport = oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, httpClient), ts).Transport
 	}
 	return github.NewClient(httpClient)
 }
@@ -52,85 +155,190 @@ func authorize(r *http.Request) {
 
 type Matcher func(path string) bool
 
+// RepositoryFile is a file extracted from an archive.
+// For symbolic links, Data holds the link target
 type RepositoryFile struct {
 	Path     string
 	FileInfo os.FileInfo
 	Data     []byte
 }
 
+// DownloadOptions defines available options to download and extract repository files
+type DownloadOptions struct {
+	// ContinueOnError keeps extracting the remaining files when one fails.
+	// Files extracted successfully are returned along with a MultiError listing the failures
+	ContinueOnError bool
+	// StrictMode turns conditions that are otherwise only reported as warnings into returned errors:
+	//  - archive entries skipped because they are not nested in the top level directory of the repository
+	//  - invalid patterns given to DownloadOptions.MatchesOneOf
+	// Per-file extraction errors are returned regardless of StrictMode. Skipped entries are reported as per-file errors,
+	// following ContinueOnError: extraction either stops at the first one or lists them all in a MultiError, along with
+	// the files extracted successfully
+	StrictMode bool
+	// Transform, when set, is called with the content of each extracted file, symbolic links excepted.
+	// The returned data is stored in place of the original content, errors are handled as per-file extraction errors
+	Transform func(path string, data []byte) ([]byte, error)
+	// MaxTotalBytes, when positive, aborts the extraction with an ErrSizeLimitExceeded once extracted files exceed it in total,
+	// submodules included
+	MaxTotalBytes int64
+	// MaxFileBytes, when positive, aborts the extraction with an ErrSizeLimitExceeded when a single file exceeds it
+	MaxFileBytes int64
+	// SkipOversizeFiles skips files larger than MaxFileBytes with a warning, instead of aborting the extraction
+	SkipOversizeFiles bool
+	// MaxUncompressedBytes, when positive, aborts the extraction with an ErrDecompressionBomb once the archive inflates beyond it,
+	// including entries that are not extracted and the archives of submodules
+	MaxUncompressedBytes int64
+	// MaxDecompressionRatio, when positive, aborts the extraction with an ErrDecompressionBomb when content inflates more than
+	// this many times its compressed size. It is checked per entry of zip archives and for the whole content of gzipped tarballs,
+	// once more than 1MiB has been inflated
+	MaxDecompressionRatio float64
+	// LineEndings converts the line endings of text files, told apart from binary ones by looking for NUL bytes.
+	// Conversions happen before Transform is called. Line endings are kept by default
+	LineEndings LineEnding
+	// submodules is the depth of nested submodules to download, see WithSubmodules
+	submodules int
+	// counters, when set, accumulates the bytes of the whole download, submodules included, for the byte limits to apply to it
+	counters *downloadCounters
+}
+
+// downloadCounters counts the bytes of a download that MaxTotalBytes and MaxUncompressedBytes limit
+type downloadCounters struct {
+	// extracted is the number of bytes extracted so far
+	extracted int64
+	// inflated is the number of bytes decompressed so far
+	inflated int64
+}
+
+// sharedCounters returns the counters of the download, fresh ones when extracting a single archive
+func (o *DownloadOptions) sharedCounters() *downloadCounters {
+	if o.counters != nil {
+		return o.counters
+	}
+	return &downloadCounters{}
+}
+
+// MatchesOneOf returns a matcher like MatchesOneOf does. In strict mode, invalid patterns are returned as an error,
+// like CompileMatcher does, instead of being reported as warnings
+func (o *DownloadOptions) MatchesOneOf(patterns ...string) (Matcher, error) {
+	if o != nil && o.StrictMode {
+		return CompileMatcher(patterns...)
+	}
+	return MatchesOneOf(patterns...), nil
+}
+
+// WithSubmodules downloads the files of submodules declared in .gitmodules, at the commit pinned by the repository,
+// into their path in the result. Submodules of submodules are downloaded up to depth levels, like actions/checkout does
+func (o *DownloadOptions) WithSubmodules(depth int) *DownloadOptions {
+	if o == nil {
+		o = &DownloadOptions{}
+	}
+	o.submodules = depth
+	return o
+}
+
 // DownloadSelectedRepositoryFiles downloads files from a given repository and granch, given that their name matches regarding the `include` function
 func DownloadSelectedRepositoryFiles(c *http.Client, owner, repo, branch string, include Matcher) map[string]RepositoryFile {
+	files, err := DownloadRepositoryFiles(c, owner, repo, branch, include, nil)
+	if err != nil {
+		core.Warningf("failed to download repository: %v", err)
+		return nil
+	}
+	return files
+}
+
+// DownloadRepositoryFiles downloads files from a given repository and branch, given that their name matches regarding the `include` function.
+// Unlike DownloadSelectedRepositoryFiles, failures are returned to the caller. When c is nil, HTTPClient is used
+func DownloadRepositoryFiles(c *http.Client, owner, repo, branch string, include Matcher, options *DownloadOptions) (map[string]RepositoryFile, error) {
+	files, _, err := downloadTarball(c, owner, repo, branch, include, options, "")
+	return files, err
+}
+
+// DownloadRepositoryFilesIfModified downloads files like DownloadRepositoryFiles, unless the tarball still has the etag
+// returned by a previous download, in which case ErrNotModified is returned. The etag of the downloaded tarball is returned
+// so that callers can store it along with the files, see CachedDownloader
+func DownloadRepositoryFilesIfModified(c *http.Client, owner, repo, branch string, include Matcher, etag string, options *DownloadOptions) (map[string]RepositoryFile, string, error) {
+	return downloadTarball(c, owner, repo, branch, include, options, etag)
+}
+
+func downloadTarball(c *http.Client, owner, repo, branch string, include Matcher, options *DownloadOptions, etag string) (map[string]RepositoryFile, string, error) {
+	if options == nil {
+		options = &DownloadOptions{}
+	}
+	if options.counters == nil {
+		// submodules share the counters of the superproject, copied along with the options
+		shared := *options
+		shared.counters = &downloadCounters{}
+		options = &shared
+	}
+	if c == nil {
+		c = baseHTTPClient()
+	}
 	u := fmt.Sprintf("https://api.github.com/repos/%s/%s/tarball/%s", owner, repo, branch)
 	core.Debugf("Downloading tarball for repo: %s", u)
 	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
-		core.Warningf("failed to download repository: %v", err)
-		return nil
+		return nil, "", err
 	}
 	authorize(req)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
 	resp, err := c.Do(req)
 	if err != nil {
-		core.Warningf("failed to download repository: %v", err)
-		return nil
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if etag != "" && resp.StatusCode == http.StatusNotModified {
+		return nil, etag, ErrNotModified
 	}
 	if resp.StatusCode != http.StatusOK {
-		core.Warningf("failed to download repository: unexpected code %d", resp.StatusCode)
-		return nil
+		return nil, "", fmt.Errorf("unexpected code %d", resp.StatusCode)
 	}
-	defer resp.Body.Close()
-	var body io.Reader = resp.Body
-	switch resp.Header.Get("Content-Type") {
-	case "application/gzip", "application/x-gzip":
-		body, err = gzip.NewReader(body)
-		if err != nil {
-			core.Warningf("failed to download repository: %v", err)
-			return nil
-		}
+	if options.submodules <= 0 {
+		files, err := readTarResponse(resp, include, 1, options)
+		return files, resp.Header.Get("ETag"), err
 	}
-	files := map[string]RepositoryFile{}
-	tr := tar.NewReader(body)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			break // End of archive
-		}
-		if err != nil {
-			core.Warningf("failed to download repository: %v", err)
-			return nil
-		}
-		if hdr.Format == tar.FormatPAX || hdr.FileInfo().IsDir() {
-			continue
-		}
-		name := strings.SplitN(hdr.Name, string(os.PathSeparator), 2)[1]
-		if include(name) {
-			core.Debugf("Downloading %v", hdr.Name)
-			b := bytes.NewBuffer(nil)
-			if _, err := io.Copy(b, tr); err != nil {
-				core.Warningf("failed to download repository: %v", err)
-				return nil
-			}
-			files[name] = RepositoryFile{
-				Path:     name,
-				FileInfo: hdr.FileInfo(),
-				Data:     b.Bytes(),
-			}
-		}
+	files, err := readTarResponse(resp, includeGitmodules(include), 1, options)
+	if err != nil {
+		return files, "", err
 	}
-	return files
+	// submodules are pinned by the repository commit, its etag changes along with them
+	files, err = downloadSubmodules(c, owner, repo, branch, files, include, options)
+	return files, resp.Header.Get("ETag"), err
 }
 
-// MatchesOneOf returns a matcher returning whether the path matches one of the provided glob patterns
-func MatchesOneOf(patterns ...string) Matcher {
+// CompileMatcher returns a matcher returning whether the path matches one of the provided POSIX regular expressions.
+// Patterns are compiled once, an error is returned when one of them is invalid
+func CompileMatcher(patterns ...string) (Matcher, error) {
+	exps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		exp, err := regexp.CompilePOSIX(p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile pattern %s: %v", p, err)
+		}
+		exps = append(exps, exp)
+	}
 	return func(path string) bool {
-		for _, p := range patterns {
-			exp, err := regexp.CompilePOSIX(p)
-			if err != nil {
-				core.Warningf("unable to compile pattern %s: %v", p, err)
-			}
+		for _, exp := range exps {
 			if exp.MatchString(path) {
 				return true
 			}
 		}
 		return false
+	}, nil
+}
+
+// MatchesOneOf returns a matcher returning whether the path matches one of the provided patterns, see CompileMatcher.
+// Invalid patterns are reported as warnings and never match
+func MatchesOneOf(patterns ...string) Matcher {
+	valid := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if _, err := regexp.CompilePOSIX(p); err != nil {
+			core.Warningf("unable to compile pattern %s: %v", p, err)
+			continue
+		}
+		valid = append(valid, p)
 	}
+	m, _ := CompileMatcher(valid...)
+	return m
 }
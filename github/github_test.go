@@ -1,8 +1,12 @@
 package github_test
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/actions-go/toolkit/github"
@@ -35,3 +39,85 @@ func TestMatchOneOf(t *testing.T) {
 	assert.True(t, github.MatchesOneOf("\\.github/settings\\..*", ".github/settings/.*")(".github/settings/branches/master/protection.json"))
 	assert.False(t, github.MatchesOneOf("\\.github/some-other.*")(".github/settings/branches/master/protection.json"))
 }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestHTTPClient(t *testing.T) {
+	previousToken := os.Getenv("GITHUB_TOKEN")
+	os.Setenv("GITHUB_TOKEN", "some-token")
+	defer os.Setenv("GITHUB_TOKEN", previousToken)
+
+	archive := bytes.NewBuffer(nil)
+	assert.NoError(t, github.WriteTarGz(archive, map[string]github.RepositoryFile{
+		"actions-go-toolkit-sha/module.go": {Data: []byte(content)},
+	}))
+	requests := []*http.Request{}
+	github.HTTPClient = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests = append(requests, r)
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: r}
+		if r.URL.Path == "/repos/actions-go/toolkit/tarball/master" {
+			resp.Header.Set("Content-Type", "application/gzip")
+			resp.Body = ioutil.NopCloser(bytes.NewReader(archive.Bytes()))
+		} else {
+			resp.Header.Set("Content-Type", "application/json")
+			resp.Body = ioutil.NopCloser(bytes.NewBufferString(`{"name": "toolkit"}`))
+		}
+		return resp, nil
+	})}
+	defer func() { github.HTTPClient = nil }()
+
+	repo, _, err := github.NewClient().Repositories.Get(context.Background(), "actions-go", "toolkit")
+	assert.NoError(t, err)
+	assert.Equal(t, "toolkit", repo.GetName())
+
+	files, err := github.DownloadRepositoryFiles(nil, "actions-go", "toolkit", "master", github.MatchesOneOf("^module.go$"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(files["module.go"].Data))
+
+	if assert.Len(t, requests, 2) {
+		for _, r := range requests {
+			assert.NotEmpty(t, r.Header.Get("Authorization"), fmt.Sprintf("token must be sent to %s", r.URL))
+		}
+	}
+}
+
+func TestCompileMatcher(t *testing.T) {
+	m, err := github.CompileMatcher("^cmd/", "\\.go$")
+	assert.NoError(t, err)
+	assert.True(t, m("cmd/main"))
+	assert.True(t, m("pkg/lib.go"))
+	assert.False(t, m("README.md"))
+
+	_, err = github.CompileMatcher("\\.go$", "(unclosed")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "(unclosed")
+	}
+
+	t.Run("MatchesOneOf ignores invalid patterns", func(t *testing.T) {
+		m := github.MatchesOneOf("(unclosed", "\\.go$")
+		assert.True(t, m("pkg/lib.go"))
+		assert.False(t, m("README.md"))
+	})
+}
+
+var benchmarkPaths = []string{"README.md", "cmd/main.go", "pkg/lib/lib.go", "docs/index.md", ".github/workflows/ci.yml"}
+
+func BenchmarkCompileMatcher(b *testing.B) {
+	m, _ := github.CompileMatcher("^docs/", "\\.go$", "^\\.github/")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m(benchmarkPaths[i%len(benchmarkPaths)])
+	}
+}
+
+// BenchmarkCompilePerPath measures compiling patterns for each path, as matchers used to do
+func BenchmarkCompilePerPath(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		m, _ := github.CompileMatcher("^docs/", "\\.go$", "^\\.github/")
+		m(benchmarkPaths[i%len(benchmarkPaths)])
+	}
+}
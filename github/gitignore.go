@@ -0,0 +1,182 @@
+package github
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+type gitignoreRule struct {
+	// base is the directory holding the .gitignore declaring the rule, empty for the repository root
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+func (r gitignoreRule) match(p string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(p, r.base+"/") {
+			return false
+		}
+		p = strings.TrimPrefix(p, r.base+"/")
+	}
+	return r.pattern.MatchString(p)
+}
+
+type gitignoreRules []gitignoreRule
+
+// ignored returns whether the path is ignored, the last matching rule wins
+func (rules gitignoreRules) ignored(p string, isDir bool) bool {
+	ignored := false
+	for _, r := range rules {
+		if r.match(p, isDir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+func (rules gitignoreRules) matcher() Matcher {
+	return func(p string) bool {
+		parts := strings.Split(p, "/")
+		// As for git, a file can't be re-included when one of its parent directories is ignored
+		for i := 1; i < len(parts); i++ {
+			if rules.ignored(strings.Join(parts[:i], "/"), true) {
+				return true
+			}
+		}
+		return rules.ignored(p, false)
+	}
+}
+
+// globToRegexp converts a gitignore glob to a regular expression, without anchors
+func globToRegexp(glob string) string {
+	s := ""
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '\\' && i+1 < len(glob):
+			i++
+			s += regexp.QuoteMeta(string(glob[i]))
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			s += "(.*/)?"
+			i += 2
+		case glob[i:] == "**" && i > 0 && glob[i-1] == '/':
+			s += ".*"
+			i++
+		case c == '*':
+			s += "[^/]*"
+		case c == '?':
+			s += "[^/]"
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				s += regexp.QuoteMeta("[")
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			s += "[" + strings.Replace(class, "\\", "\\\\", -1) + "]"
+			i += end + 1
+		default:
+			s += regexp.QuoteMeta(string(c))
+		}
+	}
+	return s
+}
+
+// parseGitignoreRule compiles a single gitignore pattern, negation aside. ok is false for blank or invalid patterns
+func parseGitignoreRule(base, line string) (rule gitignoreRule, ok bool) {
+	rule = gitignoreRule{base: base}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	prefix := "^(.*/)?"
+	// A pattern containing a separator is relative to the .gitignore location
+	if strings.Contains(line, "/") {
+		prefix = "^"
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+	exp, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+	if err != nil {
+		core.Warningf("unable to compile gitignore pattern %s: %v", line, err)
+		return rule, false
+	}
+	rule.pattern = exp
+	return rule, true
+}
+
+func parseGitignore(base, content string) gitignoreRules {
+	rules := gitignoreRules{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		// trailing spaces are ignored unless they are escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+			line = strings.TrimSuffix(line, " ")
+		}
+		if line == "" {
+			continue
+		}
+		negate := false
+		if strings.HasPrefix(line, "!") {
+			negate = true
+			line = line[1:]
+		}
+		rule, ok := parseGitignoreRule(base, line)
+		if !ok {
+			continue
+		}
+		rule.negate = negate
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GitignoreMatcher returns a matcher returning whether the path is ignored regarding the provided .gitignore content.
+// Paths are expected to be slash separated and relative to the directory holding the .gitignore
+func GitignoreMatcher(gitignoreContent string) Matcher {
+	return parseGitignore("", gitignoreContent).matcher()
+}
+
+// MatcherFromGitignore returns a matcher returning whether the path is ignored regarding all .gitignore files found in the downloaded files.
+// Rules from a nested .gitignore only apply to files in its directory and take precedence over the ones of its parents
+func MatcherFromGitignore(files map[string]RepositoryFile) Matcher {
+	ignoreFiles := []string{}
+	for name := range files {
+		if path.Base(name) == ".gitignore" {
+			ignoreFiles = append(ignoreFiles, name)
+		}
+	}
+	sort.Slice(ignoreFiles, func(i, j int) bool {
+		di, dj := strings.Count(ignoreFiles[i], "/"), strings.Count(ignoreFiles[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return ignoreFiles[i] < ignoreFiles[j]
+	})
+	rules := gitignoreRules{}
+	for _, name := range ignoreFiles {
+		base := path.Dir(name)
+		if base == "." {
+			base = ""
+		}
+		rules = append(rules, parseGitignore(base, string(files[name].Data))...)
+	}
+	return rules.matcher()
+}
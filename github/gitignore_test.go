@@ -0,0 +1,70 @@
+package github_test
+
+import (
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitignoreMatcher(t *testing.T) {
+	m := github.GitignoreMatcher(`
+# comments are skipped
+*.log
+!important.log
+build/
+/vendor
+docs/**/*.tmp
+\#hash
+`)
+	assert.True(t, m("debug.log"))
+	assert.True(t, m("some/dir/debug.log"))
+	assert.False(t, m("important.log"))
+	assert.False(t, m("some/dir/important.log"))
+	assert.False(t, m("main.go"))
+
+	t.Run("directory patterns only match directories", func(t *testing.T) {
+		assert.True(t, m("build/output.bin"))
+		assert.True(t, m("sub/build/output.bin"))
+		assert.False(t, m("build"))
+		assert.False(t, m("cmd/build"))
+	})
+	t.Run("anchored patterns only match at the root", func(t *testing.T) {
+		assert.True(t, m("vendor/github.com/lib/lib.go"))
+		assert.False(t, m("sub/vendor/lib.go"))
+	})
+	t.Run("double star matches any intermediate directories", func(t *testing.T) {
+		assert.True(t, m("docs/a.tmp"))
+		assert.True(t, m("docs/a/b/c.tmp"))
+		assert.False(t, m("other/a.tmp"))
+	})
+	t.Run("escaped characters are matched literally", func(t *testing.T) {
+		assert.True(t, m("#hash"))
+	})
+}
+
+func TestGitignoreMatcherNegation(t *testing.T) {
+	m := github.GitignoreMatcher("/generated/*\n!/generated/keep.go\n")
+	assert.True(t, m("generated/code.go"))
+	assert.False(t, m("generated/keep.go"))
+
+	t.Run("files can't be re-included when their parent directory is ignored", func(t *testing.T) {
+		m := github.GitignoreMatcher("generated/\n!generated/keep.go\n")
+		assert.True(t, m("generated/keep.go"))
+	})
+}
+
+func TestMatcherFromGitignore(t *testing.T) {
+	m := github.MatcherFromGitignore(map[string]github.RepositoryFile{
+		".gitignore":     {Path: ".gitignore", Data: []byte("*.tmp\n")},
+		"sub/.gitignore": {Path: "sub/.gitignore", Data: []byte("!keep.tmp\n/local\n")},
+		"main.go":        {Path: "main.go"},
+	})
+	assert.True(t, m("a.tmp"))
+	assert.True(t, m("keep.tmp"))
+	assert.False(t, m("sub/keep.tmp"))
+	assert.True(t, m("sub/other.tmp"))
+	assert.True(t, m("sub/local/file.go"))
+	assert.False(t, m("local/file.go"))
+	assert.False(t, m("main.go"))
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// MatchesGlobs returns a matcher returning whether the path matches one of the globs, relative to the repository root.
+// Globs follow the gitignore syntax: * and ? do not match slashes while ** matches any number of directories, like in docs/**.
+// Invalid globs are reported as warnings and never match
+func MatchesGlobs(globs ...string) Matcher {
+	exps := make([]*regexp.Regexp, 0, len(globs))
+	for _, g := range globs {
+		exp, err := regexp.Compile("^" + globToRegexp(strings.TrimPrefix(g, "/")) + "$")
+		if err != nil {
+			core.Warningf("unable to compile glob %s: %v", g, err)
+			continue
+		}
+		exps = append(exps, exp)
+	}
+	return func(path string) bool {
+		for _, exp := range exps {
+			if exp.MatchString(path) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// globPrefix returns the directories a glob is restricted to, empty when it may match files at the repository root
+func globPrefix(glob string) string {
+	parts := strings.Split(strings.TrimPrefix(glob, "/"), "/")
+	literal := []string{}
+	// the last element names files, even when it is literal
+	for _, part := range parts[:len(parts)-1] {
+		if strings.ContainsAny(part, `*?[\`) {
+			break
+		}
+		literal = append(literal, part)
+	}
+	return strings.Join(literal, "/")
+}
+
+// GlobPrefixes returns the directories the files matched by globs, see MatchesGlobs, are in, sorted and without nested
+// directories. For example docs/** and src/** are restricted to docs and src. ok is false when one of the globs may match
+// files anywhere in the repository, for example *.md or **/*.go
+func GlobPrefixes(globs ...string) (prefixes []string, ok bool) {
+	if len(globs) == 0 {
+		return nil, false
+	}
+	all := []string{}
+	for _, g := range globs {
+		p := globPrefix(g)
+		if p == "" {
+			return nil, false
+		}
+		all = append(all, p)
+	}
+	sort.Strings(all)
+	for _, p := range all {
+		if len(prefixes) > 0 {
+			last := prefixes[len(prefixes)-1]
+			if p == last || strings.HasPrefix(p, last+"/") {
+				continue
+			}
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, true
+}
@@ -0,0 +1,38 @@
+package github_test
+
+import (
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesGlobs(t *testing.T) {
+	m := github.MatchesGlobs("docs/**", "src/*.go", "/README.md")
+	assert.True(t, m("docs/index.md"))
+	assert.True(t, m("docs/api/v1/index.md"))
+	assert.True(t, m("src/main.go"))
+	assert.False(t, m("src/lib/lib.go"))
+	assert.True(t, m("README.md"))
+	assert.False(t, m("sub/README.md"))
+}
+
+func TestGlobPrefixes(t *testing.T) {
+	prefixes, ok := github.GlobPrefixes("docs/**", "src/**")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"docs", "src"}, prefixes)
+
+	prefixes, ok = github.GlobPrefixes("src/cmd/*.go", "docs/api/**/*.md", "src/**", "/docs/README.md")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"docs", "src"}, prefixes, "nested directories are covered by their parents")
+
+	prefixes, ok = github.GlobPrefixes("docs/api/**", "docs/*/index.md")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"docs"}, prefixes)
+
+	for _, globs := range [][]string{{"docs/**", "*.md"}, {"**/*.go"}, {"README.md"}, {"*/docs/**"}, {}} {
+		prefixes, ok := github.GlobPrefixes(globs...)
+		assert.False(t, ok, "%v may match files anywhere", globs)
+		assert.Nil(t, prefixes)
+	}
+}
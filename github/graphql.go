@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type graphQLResponse struct {
+	Data   interface{}    `json:"data"`
+	Errors []graphQLError `json:"errors"`
+}
+
+// graphQLURL returns the GraphQL endpoint of the server the GitHub client targets.
+// On GitHub Enterprise Server, the REST API is served under /api/v3 and GraphQL under /api/graphql
+func graphQLURL() string {
+	u := *GitHub.BaseURL
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+	} else {
+		u.Path += "graphql"
+	}
+	return u.String()
+}
+
+// graphQL runs a GraphQL query or mutation and decodes its data into out.
+// Errors reported by GitHub are returned, as an ErrUnauthorized when the token lacks permissions
+func graphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	req, err := GitHub.NewRequest(http.MethodPost, graphQLURL(), &graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+	result := &graphQLResponse{Data: out}
+	resp, err := GitHub.Do(ctx, req, result)
+	if err != nil {
+		return PermissionError(resp, err)
+	}
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, len(result.Errors))
+	forbidden := false
+	for i, e := range result.Errors {
+		messages[i] = e.Message
+		forbidden = forbidden || e.Type == "FORBIDDEN"
+	}
+	err = fmt.Errorf("%s", strings.Join(messages, "; "))
+	if forbidden {
+		return &ErrUnauthorized{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package github
+
+import (
+	"sync"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+var (
+	groupLock  sync.Mutex
+	groupDepth int
+)
+
+// enterGroup returns whether a new group must be opened, GitHub does not support nested groups
+func enterGroup() bool {
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	groupDepth++
+	return groupDepth == 1
+}
+
+func leaveGroup() {
+	groupLock.Lock()
+	defer groupLock.Unlock()
+	groupDepth--
+}
+
+// Group runs fn inside a foldable log group and logs how long it took.
+// The group is closed even if fn panics. As GitHub does not support nested groups, groups started
+// from within another group are flattened into it and only log their title and duration
+func Group(title string, fn func() error) error {
+	outermost := enterGroup()
+	start := now()
+	if outermost {
+		core.StartGroup(title)
+	} else {
+		core.Info(title)
+	}
+	defer func() {
+		core.Infof("%s took %v", title, now().Sub(start))
+		if outermost {
+			core.EndGroup()
+		}
+		leaveGroup()
+	}()
+	return fn()
+}
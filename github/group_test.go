@@ -0,0 +1,39 @@
+package github
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	core.SetStdout(b)
+	defer core.SetStdout(os.Stdout)
+
+	err := Group("outer", func() error {
+		return Group("inner", func() error {
+			return errors.New("failed")
+		})
+	})
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, 1, bytes.Count(b.Bytes(), []byte("::group::")))
+	assert.Contains(t, b.String(), "::group::outer\n")
+	assert.Equal(t, 1, bytes.Count(b.Bytes(), []byte("::endgroup::")))
+
+	t.Run("the group is closed when the function panics", func(t *testing.T) {
+		b.Reset()
+		assert.Panics(t, func() {
+			Group("panicking", func() error {
+				panic("boom")
+			})
+		})
+		assert.Contains(t, b.String(), "::group::panicking\n")
+		assert.Contains(t, b.String(), "::endgroup::\n")
+		assert.Equal(t, 0, groupDepth)
+	})
+}
@@ -0,0 +1,90 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// compileGlobs compiles slash separated globs, supporting ** to match any number of directories
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	exps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		exp, err := regexp.Compile("^" + globToRegexp(filepath.ToSlash(pattern)) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+		exps = append(exps, exp)
+	}
+	return exps, nil
+}
+
+func hashFile(path string) (string, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, fd); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFiles returns a SHA-256 of the workspace files matching any of the patterns, like the hashFiles expression.
+// Patterns are globs relative to the workspace where ** matches any number of directories.
+// The hash only depends on the matched paths and their content, an empty string is returned when no file matches
+func HashFiles(patterns ...string) (string, error) {
+	exps, err := compileGlobs(patterns)
+	if err != nil {
+		return "", err
+	}
+	root := Workspace()
+	hashes := map[string]string{}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, exp := range exps {
+			if exp.MatchString(rel) {
+				h, err := hashFile(path)
+				if err != nil {
+					return err
+				}
+				hashes[rel] = h
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash files: %v", err)
+	}
+	if len(hashes) == 0 {
+		return "", nil
+	}
+	paths := make([]string, 0, len(hashes))
+	for p := range hashes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", p, hashes[p])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hash-files")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer mockEnv(map[string]string{"GITHUB_WORKSPACE": dir})()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", "pkg"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.sum"), []byte("root"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "pkg", "go.sum"), []byte("nested"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+
+	h, err := HashFiles("**/go.sum", "*.go")
+	require.NoError(t, err)
+	assert.Len(t, h, 64)
+
+	reversed, err := HashFiles("*.go", "**/go.sum")
+	require.NoError(t, err)
+	assert.Equal(t, h, reversed, "the hash must not depend on the patterns order")
+
+	sums, err := HashFiles("**/go.sum")
+	require.NoError(t, err)
+	assert.NotEqual(t, h, sums)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "pkg", "go.sum"), []byte("changed"), 0644))
+	changed, err := HashFiles("**/go.sum", "*.go")
+	require.NoError(t, err)
+	assert.NotEqual(t, h, changed, "content changes must change the hash")
+
+	none, err := HashFiles("*.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "", none)
+}
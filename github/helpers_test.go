@@ -0,0 +1,93 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// mockGitHub points the package client to a test server serving mux, the returned function restores the previous client
+func mockGitHub(mux *http.ServeMux) func() {
+	server := httptest.NewServer(mux)
+	previous := GitHub
+	GitHub = github.NewClient(nil)
+	u, _ := url.Parse(server.URL + "/")
+	GitHub.BaseURL = u
+	GitHub.UploadURL = u
+	return func() {
+		GitHub = previous
+		server.Close()
+	}
+}
+
+// mockContext replaces the action context, the returned function restores the previous one
+func mockContext(ctx ActionContext) func() {
+	previous := Context
+	Context = ctx
+	return func() {
+		Context = previous
+	}
+}
+
+// setEnv sets environment variables, the returned function restores their previous value
+func setEnv(env map[string]string) func() {
+	restore := map[string]*string{}
+	for k, v := range env {
+		if previous, ok := os.LookupEnv(k); ok {
+			restore[k] = &previous
+		} else {
+			restore[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range restore {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
+
+// mockEnv makes env accessors read variables from env instead of the process environment, the returned function restores it
+func mockEnv(env map[string]string) func() {
+	previous := getenv
+	getenv = func(name string) string {
+		return env[name]
+	}
+	return func() {
+		getenv = previous
+	}
+}
+
+// mockClock makes waits return immediately and records their duration in waits.
+// The time returned by now advances by the waited duration. The returned function restores the real clock
+func mockClock(waits *[]time.Duration) func() {
+	previousNow, previousAfter := now, after
+	lock := sync.Mutex{}
+	current := time.Now()
+	now = func() time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		return current
+	}
+	after = func(d time.Duration) <-chan time.Time {
+		lock.Lock()
+		defer lock.Unlock()
+		*waits = append(*waits, d)
+		current = current.Add(d)
+		c := make(chan time.Time, 1)
+		c <- current
+		return c
+	}
+	return func() {
+		now, after = previousNow, previousAfter
+	}
+}
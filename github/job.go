@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// RunURL returns the URL of the page displaying the current workflow run, including the attempt on re-runs
+func RunURL() string {
+	u := fmt.Sprintf("%s/%s/actions/runs/%d", ServerURL(), Repository(), RunID())
+	if attempt := RunAttempt(); attempt > 1 {
+		u += fmt.Sprintf("/attempts/%d", attempt)
+	}
+	return u
+}
+
+// CommitURL returns the URL of the page displaying a commit of the repository running the workflow
+func CommitURL(sha string) string {
+	return fmt.Sprintf("%s/%s/commit/%s", ServerURL(), Repository(), sha)
+}
+
+// CompareURL returns the URL of the page comparing two refs or commits of the repository running the workflow
+func CompareURL(base, head string) string {
+	return fmt.Sprintf("%s/%s/compare/%s...%s", ServerURL(), Repository(), base, head)
+}
+
+// JobURL returns the URL of the page displaying the current job.
+// The runner does not expose the job identifier, hence the URL points to the workflow run page.
+// Use CurrentJob to get the exact job URL
+func JobURL() string {
+	return RunURL()
+}
+
+// jobNameMatches returns whether the job name reported by the API corresponds to the job identifier.
+// The API reports the display name, matrix jobs are suffixed with their matrix values in parenthesis
+func jobNameMatches(name, job string) bool {
+	return name == job || strings.HasPrefix(name, job+" (")
+}
+
+// runJob is a job of a workflow run along with the runner it was assigned to, go-github does not decode it
+type runJob struct {
+	github.WorkflowJob
+	RunnerName string `json:"runner_name,omitempty"`
+}
+
+// listRunJobs returns the jobs of the latest attempt of the current workflow run
+func listRunJobs(ctx context.Context) ([]*runJob, error) {
+	all := []*runJob{}
+	page := 1
+	for {
+		u := fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?filter=latest&per_page=100&page=%d", Context.Repo.Owner, Context.Repo.Repo, RunID(), page)
+		req, err := GitHub.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		jobs := struct {
+			Jobs []*runJob `json:"jobs"`
+		}{}
+		resp, err := GitHub.Do(ctx, req, &jobs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs of run %d: %v", RunID(), err)
+		}
+		all = append(all, jobs.Jobs...)
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+	return all, nil
+}
+
+// RunJobs returns the jobs of the latest attempt of the current workflow run
+func RunJobs(ctx context.Context) ([]*github.WorkflowJob, error) {
+	jobs, err := listRunJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]*github.WorkflowJob, 0, len(jobs))
+	for _, j := range jobs {
+		all = append(all, &j.WorkflowJob)
+	}
+	return all, nil
+}
+
+// AllJobsSucceeded returns whether every completed job of the current workflow run succeeded, along with the names of
+// the ones that did not. Skipped jobs are ignored, as are jobs not completed yet, the one calling AllJobsSucceeded included
+func AllJobsSucceeded(ctx context.Context) (bool, []string, error) {
+	jobs, err := RunJobs(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+	failed := []string{}
+	for _, j := range jobs {
+		if j.GetStatus() != "completed" {
+			continue
+		}
+		switch j.GetConclusion() {
+		case "success", "skipped":
+		default:
+			failed = append(failed, j.GetName())
+		}
+	}
+	return len(failed) == 0, failed, nil
+}
+
+// CurrentJob retrieves the job currently running from the latest attempt of the current workflow run.
+// The runner only exposes the job identifier, GITHUB_JOB, while the API reports job names. The job in progress on
+// the runner, named by RUNNER_NAME, is returned first. Otherwise jobs are matched by name, which only works when the
+// job name is the job identifier, or is not set. When several jobs match, matrix jobs for example, the first one
+// still in progress is returned
+func CurrentJob(ctx context.Context) (*github.WorkflowJob, error) {
+	job := Job()
+	if job == "" {
+		return nil, fmt.Errorf("unable to find current job: GITHUB_JOB is not set")
+	}
+	jobs, err := listRunJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if runner := getenv("RUNNER_NAME"); runner != "" {
+		for _, j := range jobs {
+			if j.GetStatus() == "in_progress" && j.RunnerName == runner {
+				return &j.WorkflowJob, nil
+			}
+		}
+	}
+	var found *github.WorkflowJob
+	for _, j := range jobs {
+		if !jobNameMatches(j.GetName(), job) {
+			continue
+		}
+		if j.GetStatus() == "in_progress" {
+			return &j.WorkflowJob, nil
+		}
+		if found == nil {
+			found = &j.WorkflowJob
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unable to find job %s in run %d: no job in progress on runner %q and no job named after the job identifier",
+			job, RunID(), getenv("RUNNER_NAME"))
+	}
+	return found, nil
+}
+
+// PreviousAttemptConclusion returns the conclusion of the previous attempt of the current workflow run, for example success or failure.
+// An error is returned when the run is not a re-run
+func PreviousAttemptConclusion(ctx context.Context) (string, error) {
+	attempt := RunAttempt()
+	if attempt <= 1 {
+		return "", fmt.Errorf("run %d has no previous attempt", RunID())
+	}
+	u := fmt.Sprintf("repos/%s/%s/actions/runs/%d/attempts/%d", Context.Repo.Owner, Context.Repo.Repo, RunID(), attempt-1)
+	req, err := GitHub.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	run := &github.WorkflowRun{}
+	if _, err := GitHub.Do(ctx, req, run); err != nil {
+		return "", fmt.Errorf("failed to get attempt %d of run %d: %v", attempt-1, RunID(), err)
+	}
+	return run.GetConclusion(), nil
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobURL(t *testing.T) {
+	defer mockEnv(map[string]string{
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_REPOSITORY": "actions-go/toolkit",
+		"GITHUB_RUN_ID":     "1234",
+	})()
+	assert.Equal(t, "https://github.com/actions-go/toolkit/actions/runs/1234", JobURL())
+}
+
+func TestRunURL(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_REPOSITORY": "actions-go/toolkit",
+		"GITHUB_RUN_ID":     "1234",
+	}
+	defer mockEnv(env)()
+	assert.Equal(t, "https://github.com/actions-go/toolkit/actions/runs/1234", RunURL())
+	assert.Equal(t, "https://github.com/actions-go/toolkit/commit/d74fd51", CommitURL("d74fd51"))
+	assert.Equal(t, "https://github.com/actions-go/toolkit/compare/v1.0.0...main", CompareURL("v1.0.0", "main"))
+
+	t.Run("on GitHub Enterprise Server", func(t *testing.T) {
+		env["GITHUB_SERVER_URL"] = "https://ghes.example.com/"
+		env["GITHUB_RUN_ATTEMPT"] = "2"
+		assert.Equal(t, "https://ghes.example.com/actions-go/toolkit/actions/runs/1234/attempts/2", RunURL())
+		assert.Equal(t, "https://ghes.example.com/actions-go/toolkit/commit/d74fd51", CommitURL("d74fd51"))
+		assert.Equal(t, "https://ghes.example.com/actions-go/toolkit/compare/v1.0.0...main", CompareURL("v1.0.0", "main"))
+	})
+}
+
+func TestCurrentJob(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/1234/jobs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "latest", r.URL.Query().Get("filter"))
+		fmt.Fprint(w, `{"total_count": 5, "jobs": [
+			{"id": 1, "name": "build", "status": "completed", "html_url": "https://github.com/actions-go/toolkit/runs/1"},
+			{"id": 2, "name": "test (ubuntu-latest)", "status": "completed", "html_url": "https://github.com/actions-go/toolkit/runs/2"},
+			{"id": 3, "name": "test (macos-latest)", "status": "in_progress", "html_url": "https://github.com/actions-go/toolkit/runs/3"},
+			{"id": 4, "name": "testing", "status": "in_progress", "html_url": "https://github.com/actions-go/toolkit/runs/4"},
+			{"id": 5, "name": "Deploy to production", "status": "in_progress", "runner_name": "GitHub Actions 5"}
+		]}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	env := map[string]string{"GITHUB_RUN_ID": "1234", "GITHUB_JOB": "test"}
+	defer mockEnv(env)()
+
+	job, err := CurrentJob(context.Background())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, job.GetID())
+	assert.Equal(t, "https://github.com/actions-go/toolkit/runs/3", job.GetHTMLURL())
+
+	env["GITHUB_JOB"] = "build"
+	job, err = CurrentJob(context.Background())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, job.GetID())
+
+	env["GITHUB_JOB"] = "deploy"
+	_, err = CurrentJob(context.Background())
+	assert.Error(t, err)
+
+	env["RUNNER_NAME"] = "GitHub Actions 5"
+	job, err = CurrentJob(context.Background())
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, job.GetID(), "jobs with a display name are found through their runner")
+}
+
+func TestAllJobsSucceeded(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/1234/jobs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "latest", r.URL.Query().Get("filter"))
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"total_count": 5, "jobs": [
+				{"id": 4, "name": "deploy", "status": "completed", "conclusion": "skipped"},
+				{"id": 5, "name": "report", "status": "in_progress"}
+			]}`)
+			return
+		}
+		w.Header().Set("Link", `<https://api.github.com/repositories/1/actions/runs/1234/jobs?filter=latest&page=2>; rel="next"`)
+		fmt.Fprint(w, `{"total_count": 5, "jobs": [
+			{"id": 1, "name": "build", "status": "completed", "conclusion": "success"},
+			{"id": 2, "name": "test (ubuntu-latest)", "status": "completed", "conclusion": "failure"},
+			{"id": 3, "name": "test (macos-latest)", "status": "completed", "conclusion": "cancelled"}
+		]}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	defer mockEnv(map[string]string{"GITHUB_RUN_ID": "1234"})()
+
+	jobs, err := RunJobs(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 5)
+
+	ok, failed, err := AllJobsSucceeded(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"test (ubuntu-latest)", "test (macos-latest)"}, failed)
+}
+
+func TestRunAttempt(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/1234/attempts/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1234, "run_attempt": 2, "status": "completed", "conclusion": "failure"}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	env := map[string]string{"GITHUB_RUN_ID": "1234", "GITHUB_RUN_ATTEMPT": "1"}
+	defer mockEnv(env)()
+
+	assert.EqualValues(t, 1, RunAttempt())
+	assert.False(t, IsRerun())
+	_, err := PreviousAttemptConclusion(context.Background())
+	assert.Error(t, err)
+
+	env["GITHUB_RUN_ATTEMPT"] = "3"
+	assert.EqualValues(t, 3, RunAttempt())
+	assert.True(t, IsRerun())
+	conclusion, err := PreviousAttemptConclusion(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "failure", conclusion)
+
+	env["GITHUB_RUN_ATTEMPT"] = ""
+	assert.EqualValues(t, 1, RunAttempt())
+}
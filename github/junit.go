@@ -0,0 +1,90 @@
+package github
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      string        `xml:"line,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+}
+
+// junitSuite decodes both testsuites and testsuite elements, suites may be nested
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	File   string       `xml:"file,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+func (s junitSuite) annotations() []Annotation {
+	anns := []Annotation{}
+	for _, c := range s.Cases {
+		failure := c.Failure
+		if failure == nil {
+			failure = c.Error
+		}
+		if failure == nil {
+			continue
+		}
+		path := c.File
+		if path == "" {
+			path = s.File
+		}
+		if path == "" {
+			path = c.ClassName
+		}
+		line, err := strconv.Atoi(c.Line)
+		if err != nil || line <= 0 {
+			line = 1
+		}
+		message := strings.TrimSpace(failure.Message)
+		if message == "" {
+			message = strings.TrimSpace(failure.Text)
+		}
+		if message == "" {
+			message = "test failed"
+		}
+		name := c.Name
+		if c.ClassName != "" {
+			name = c.ClassName + "." + c.Name
+		}
+		anns = append(anns, Annotation{
+			Path:       path,
+			StartLine:  line,
+			Level:      AnnotationFailure,
+			Title:      name,
+			Message:    message,
+			RawDetails: strings.TrimSpace(failure.Text),
+		})
+	}
+	for _, nested := range s.Suites {
+		anns = append(anns, nested.annotations()...)
+	}
+	return anns
+}
+
+// AnnotationsFromJUnit returns a failure annotation for each failed test case of a JUnit XML report.
+// Annotations point to the file and line of the test case when the report provides them, to its class name
+// and first line otherwise. Use CreateCheckRun to report them in a check run
+func AnnotationsFromJUnit(r io.Reader) ([]Annotation, error) {
+	report := junitSuite{}
+	if err := xml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to parse JUnit report: %v", err)
+	}
+	return report.annotations(), nil
+}
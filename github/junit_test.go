@@ -0,0 +1,75 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationsFromJUnit(t *testing.T) {
+	fd, err := os.Open("junit_report.xml")
+	require.NoError(t, err)
+	defer fd.Close()
+
+	anns, err := AnnotationsFromJUnit(fd)
+	require.NoError(t, err)
+	assert.Equal(t, []Annotation{
+		{
+			Path:       "github/github_test.go",
+			StartLine:  25,
+			Level:      AnnotationFailure,
+			Title:      "github.TestDownload",
+			Message:    "expected 1 file, got 0",
+			RawDetails: `github_test.go:27: Error: "map[]" should have 1 item(s)`,
+		},
+		{
+			Path:       "cache",
+			StartLine:  1,
+			Level:      AnnotationFailure,
+			Title:      "cache.TestEnsureDestDir",
+			Message:    "permission denied",
+			RawDetails: "permission denied",
+		},
+	}, anns)
+
+	_, err = AnnotationsFromJUnit(strings.NewReader("<testsuites><testsuite>"))
+	assert.Error(t, err)
+}
+
+func TestCreateCheckRun(t *testing.T) {
+	fd, err := os.Open("junit_report.xml")
+	require.NoError(t, err)
+	defer fd.Close()
+	anns, err := AnnotationsFromJUnit(fd)
+	require.NoError(t, err)
+
+	updates := []github.UpdateCheckRunOptions{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 42, "name": "tests", "status": "in_progress"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/check-runs/42", func(w http.ResponseWriter, r *http.Request) {
+		opts := github.UpdateCheckRunOptions{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		updates = append(updates, opts)
+		fmt.Fprintf(w, `{"id": 42, "name": "tests", "status": "%s", "conclusion": "%s"}`, opts.GetStatus(), opts.GetConclusion())
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{SHA: "some-sha", Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	run, err := CreateCheckRun(context.Background(), "tests", "failure", "2 tests failed", anns)
+	require.NoError(t, err)
+	assert.Equal(t, "failure", run.GetConclusion())
+	if assert.Len(t, updates, 2) {
+		assert.Len(t, updates[0].Output.Annotations, 2)
+		assert.Equal(t, "2 tests failed", updates[1].Output.GetSummary())
+	}
+}
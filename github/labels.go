@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// labelAlreadyExists returns whether a label creation failed because the label has been created concurrently
+func labelAlreadyExists(err error) bool {
+	e, ok := err.(*github.ErrorResponse)
+	if !ok || e.Response == nil || e.Response.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, detail := range e.Errors {
+		if detail.Code == "already_exists" {
+			return true
+		}
+	}
+	return false
+}
+
+// labelURL returns the API URL of the label name, go-github does not escape label names holding a / or a ?
+func labelURL(name string) string {
+	return fmt.Sprintf("repos/%s/%s/labels/%s", Context.Repo.Owner, Context.Repo.Repo, url.PathEscape(name))
+}
+
+// getLabel returns the label name of the repository running the workflow
+func getLabel(ctx context.Context, name string) (*github.Label, *github.Response, error) {
+	req, err := GitHub.NewRequest(http.MethodGet, labelURL(name), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	label := &github.Label{}
+	resp, err := GitHub.Do(ctx, req, label)
+	return label, resp, err
+}
+
+// editLabel updates the label name of the repository running the workflow
+func editLabel(ctx context.Context, name string, update *github.Label) (*github.Label, error) {
+	label := &github.Label{}
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		req, err := GitHub.NewRequest(http.MethodPatch, labelURL(name), update)
+		if err != nil {
+			return nil, err
+		}
+		return GitHub.Do(ctx, req, label)
+	})
+	return label, err
+}
+
+// EnsureLabel returns the label name of the repository running the workflow, creating it when missing.
+// When color or description are not empty and differ from the existing label, the label is updated.
+// It is safe to call it concurrently from several jobs
+func EnsureLabel(ctx context.Context, name, color, description string) (*github.Label, error) {
+	color = strings.TrimPrefix(color, "#")
+	label, resp, err := getLabel(ctx, name)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		err = RetryRateLimited(ctx, func() (*github.Response, error) {
+			var resp *github.Response
+			var err error
+			label, resp, err = GitHub.Issues.CreateLabel(ctx, Context.Repo.Owner, Context.Repo.Repo, &github.Label{
+				Name:        github.String(name),
+				Color:       optionalString(color),
+				Description: optionalString(description),
+			})
+			return resp, err
+		})
+		if err == nil {
+			return label, nil
+		}
+		if !labelAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create label %s: %v", name, err)
+		}
+		label, _, err = getLabel(ctx, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label %s: %v", name, err)
+	}
+	update := &github.Label{Name: github.String(name)}
+	changed := false
+	if color != "" && !strings.EqualFold(color, label.GetColor()) {
+		update.Color = github.String(color)
+		changed = true
+	}
+	if description != "" && description != label.GetDescription() {
+		update.Description = github.String(description)
+		changed = true
+	}
+	if !changed {
+		return label, nil
+	}
+	label, err = editLabel(ctx, name, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update label %s: %v", name, err)
+	}
+	return label, nil
+}
+
+// AddLabels adds labels to an issue or pull request of the repository running the workflow, creating missing labels first
+func AddLabels(ctx context.Context, number int, labels ...string) error {
+	for _, name := range labels {
+		if _, err := EnsureLabel(ctx, name, "", ""); err != nil {
+			return err
+		}
+	}
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		_, resp, err := GitHub.Issues.AddLabelsToIssue(ctx, Context.Repo.Owner, Context.Repo.Repo, number, labels)
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add labels %s to issue %d: %v", strings.Join(labels, ", "), number, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func labelNotFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w, `{"message": "Not Found"}`)
+}
+
+func TestEnsureLabel(t *testing.T) {
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	t.Run("missing labels are created", func(t *testing.T) {
+		created := &github.Label{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/labels/stale", func(w http.ResponseWriter, r *http.Request) {
+			labelNotFound(w)
+		})
+		mux.HandleFunc("/repos/actions-go/toolkit/labels", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(created))
+			json.NewEncoder(w).Encode(created)
+		})
+		defer mockGitHub(mux)()
+
+		label, err := EnsureLabel(context.Background(), "stale", "#ededed", "No recent activity")
+		require.NoError(t, err)
+		assert.Equal(t, "stale", label.GetName())
+		assert.Equal(t, "ededed", created.GetColor())
+		assert.Equal(t, "No recent activity", created.GetDescription())
+	})
+
+	t.Run("existing labels are updated when they differ", func(t *testing.T) {
+		edits := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/labels/stale", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPatch {
+				edits++
+				update := &github.Label{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(update))
+				assert.Equal(t, "ff0000", update.GetColor())
+				assert.Nil(t, update.Description, "unchanged fields must not be sent")
+				fmt.Fprint(w, `{"name": "stale", "color": "ff0000", "description": "No recent activity"}`)
+				return
+			}
+			fmt.Fprint(w, `{"name": "stale", "color": "EDEDED", "description": "No recent activity"}`)
+		})
+		defer mockGitHub(mux)()
+
+		label, err := EnsureLabel(context.Background(), "stale", "ededed", "No recent activity")
+		require.NoError(t, err)
+		assert.Equal(t, 0, edits, "colors are compared case insensitively")
+		assert.Equal(t, "EDEDED", label.GetColor())
+
+		label, err = EnsureLabel(context.Background(), "stale", "ff0000", "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, edits)
+		assert.Equal(t, "ff0000", label.GetColor())
+	})
+
+	t.Run("labels created concurrently are returned", func(t *testing.T) {
+		gets := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/labels/stale", func(w http.ResponseWriter, r *http.Request) {
+			gets++
+			if gets == 1 {
+				labelNotFound(w)
+				return
+			}
+			fmt.Fprint(w, `{"name": "stale", "color": "ededed"}`)
+		})
+		mux.HandleFunc("/repos/actions-go/toolkit/labels", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Validation Failed", "errors": [{"resource": "Label", "code": "already_exists", "field": "name"}]}`)
+		})
+		defer mockGitHub(mux)()
+
+		label, err := EnsureLabel(context.Background(), "stale", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "ededed", label.GetColor())
+		assert.Equal(t, 2, gets)
+	})
+	t.Run("label names are escaped", func(t *testing.T) {
+		edits := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/labels/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.EscapedPath() != "/repos/actions-go/toolkit/labels/kind%2Fbug" {
+				labelNotFound(w)
+				return
+			}
+			if r.Method == http.MethodPatch {
+				edits++
+				fmt.Fprint(w, `{"name": "kind/bug", "color": "ff0000"}`)
+				return
+			}
+			fmt.Fprint(w, `{"name": "kind/bug", "color": "ededed"}`)
+		})
+		defer mockGitHub(mux)()
+
+		label, err := EnsureLabel(context.Background(), "kind/bug", "ff0000", "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, edits)
+		assert.Equal(t, "ff0000", label.GetColor())
+	})
+}
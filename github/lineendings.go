@@ -0,0 +1,40 @@
+package github
+
+import (
+	"bytes"
+)
+
+// LineEnding selects how line endings of text files are written on extraction, see DownloadOptions
+type LineEnding int
+
+const (
+	// LineEndingsKeep keeps line endings as stored in the repository
+	LineEndingsKeep LineEnding = iota
+	// LineEndingsLF converts line endings of text files to \n
+	LineEndingsLF
+	// LineEndingsCRLF converts line endings of text files to \r\n, like git autocrlf does on Windows
+	LineEndingsCRLF
+)
+
+// binarySniffLength is the number of leading bytes inspected to tell binary files apart, as git does
+const binarySniffLength = 8000
+
+// isBinary returns whether data looks like binary content, that is it has a NUL byte within its first 8000 bytes
+func isBinary(data []byte) bool {
+	if len(data) > binarySniffLength {
+		data = data[:binarySniffLength]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// normalizeLineEndings converts the line endings of data, binary content is returned untouched
+func normalizeLineEndings(data []byte, ending LineEnding) []byte {
+	if ending == LineEndingsKeep || isBinary(data) {
+		return data
+	}
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	if ending == LineEndingsCRLF {
+		data = bytes.Replace(data, []byte("\n"), []byte("\r\n"), -1)
+	}
+	return data
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/google/go-github/v32/github"
+)
+
+// emptyTreeSHA is the SHA of the empty git tree, lock commits point to it as they carry no file
+const emptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+const lockExpiresPrefix = "expires: "
+
+// lockReleaseGrace is how long a released lock stays held before its reference is deleted, so that a lock taken
+// meanwhile by another run is not deleted along
+const lockReleaseGrace = time.Minute
+
+// lockRef returns the reference backing the lock name, outside of branches and tags namespaces
+func lockRef(name string) string {
+	return "locks/" + name
+}
+
+// createLockCommit creates a commit recording the holder of the lock name and when it expires, action being lock or release.
+// When breaking a stale lock or releasing one, the commit is created on top of the current one so that the lock reference is
+// only moved when it still points to it
+func createLockCommit(ctx context.Context, action, name string, expires time.Time, parents ...string) (string, error) {
+	message := fmt.Sprintf("%s %s\n\nholder: %s\n%s%s", action, name, RunURL(), lockExpiresPrefix, expires.UTC().Format(time.RFC3339))
+	commit := &github.Commit{Message: github.String(message), Tree: &github.Tree{SHA: github.String(emptyTreeSHA)}}
+	for _, p := range parents {
+		commit.Parents = append(commit.Parents, &github.Commit{SHA: github.String(p)})
+	}
+	var created *github.Commit
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		created, resp, err = GitHub.Git.CreateCommit(ctx, Context.Repo.Owner, Context.Repo.Repo, commit)
+		return resp, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit for lock %s: %v", name, err)
+	}
+	return created.GetSHA(), nil
+}
+
+// lockExpiry returns when the lock recorded in a lock commit message expires
+func lockExpiry(message string) (time.Time, error) {
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, lockExpiresPrefix) {
+			return time.Parse(time.RFC3339, strings.TrimPrefix(line, lockExpiresPrefix))
+		}
+	}
+	return time.Time{}, fmt.Errorf("no expiry in lock commit")
+}
+
+// lockHolder returns the commit the lock reference of name points to, an empty string when the lock is free
+func lockHolder(ctx context.Context, name string) (string, error) {
+	ref, resp, err := GitHub.Git.GetRef(ctx, Context.Repo.Owner, Context.Repo.Repo, lockRef(name))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get lock %s: %v", name, err)
+	}
+	return ref.GetObject().GetSHA(), nil
+}
+
+// tryAcquireLock makes one attempt at taking the lock name. retry is true when the lock was released in the meantime
+func tryAcquireLock(ctx context.Context, name string, ttl time.Duration) (sha string, retry bool, err error) {
+	holder, err := lockHolder(ctx, name)
+	if err != nil {
+		return "", false, err
+	}
+	var status int
+	if holder == "" {
+		sha, err := createLockCommit(ctx, "lock", name, now().Add(ttl))
+		if err != nil {
+			return "", false, err
+		}
+		err = RetryRateLimited(ctx, func() (*github.Response, error) {
+			_, resp, err := GitHub.Git.CreateRef(ctx, Context.Repo.Owner, Context.Repo.Repo, &github.Reference{
+				Ref:    github.String("refs/" + lockRef(name)),
+				Object: &github.GitObject{SHA: github.String(sha)},
+			})
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			return resp, err
+		})
+		if status == http.StatusUnprocessableEntity {
+			// another run created the lock first
+			return "", true, nil
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("failed to create lock %s: %v", name, err)
+		}
+		return sha, false, nil
+	}
+	commit, _, err := GitHub.Git.GetCommit(ctx, Context.Repo.Owner, Context.Repo.Repo, holder)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get lock %s: %v", name, err)
+	}
+	expires, err := lockExpiry(commit.GetMessage())
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read lock %s: %v", name, err)
+	}
+	if now().Before(expires) {
+		return "", false, nil
+	}
+	core.Warningf("Breaking lock %s, expired %s ago: %s", name, now().Sub(expires).Round(time.Second), strings.TrimSpace(commit.GetMessage()))
+	sha, err = createLockCommit(ctx, "lock", name, now().Add(ttl), holder)
+	if err != nil {
+		return "", false, err
+	}
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		_, resp, err := GitHub.Git.UpdateRef(ctx, Context.Repo.Owner, Context.Repo.Repo, &github.Reference{
+			Ref:    github.String("refs/" + lockRef(name)),
+			Object: &github.GitObject{SHA: github.String(sha)},
+		}, false)
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		return resp, err
+	})
+	if status == http.StatusUnprocessableEntity {
+		// another run broke the stale lock first, or it was released before being re-created
+		return "", true, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to break lock %s: %v", name, err)
+	}
+	return sha, false, nil
+}
+
+// releaseLock frees the lock name held with the lock commit sha. The reference is first moved to a release commit on
+// top of sha, which only succeeds while the lock is still held, and is then deleted
+func releaseLock(ctx context.Context, name, sha string) error {
+	released, err := createLockCommit(ctx, "release", name, now().Add(lockReleaseGrace), sha)
+	if err != nil {
+		return err
+	}
+	var status int
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		_, resp, err := GitHub.Git.UpdateRef(ctx, Context.Repo.Owner, Context.Repo.Repo, &github.Reference{
+			Ref:    github.String("refs/" + lockRef(name)),
+			Object: &github.GitObject{SHA: github.String(released)},
+		}, false)
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		return resp, err
+	})
+	if status == http.StatusUnprocessableEntity {
+		return fmt.Errorf("lock %s is no longer held, it expired and was taken by another run", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %v", name, err)
+	}
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		return GitHub.Git.DeleteRef(ctx, Context.Repo.Owner, Context.Repo.Repo, lockRef(name))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %v", name, err)
+	}
+	return nil
+}
+
+// AcquireLock tries to take the lock name of the repository running the workflow, for example to serialize deployments.
+// It does not wait for the lock to be free: acquired is false when another run holds it.
+// Locks are references under refs/locks pointing to a commit recording the holder and the lock expiry, ttl after it
+// was acquired. Expired locks are broken, and creating or moving the reference only succeeds for a single run.
+// release moves the lock to a release commit, held for another minute, and deletes it. It returns an error, leaving the
+// lock untouched, when the lock expired and was taken by another run meanwhile.
+// The token needs the `contents: write` permission
+func AcquireLock(ctx context.Context, name string, ttl time.Duration) (release func() error, acquired bool, err error) {
+	var sha string
+	for attempt := 0; attempt < 3; attempt++ {
+		var retry bool
+		sha, retry, err = tryAcquireLock(ctx, name, ttl)
+		if err != nil || !retry {
+			break
+		}
+	}
+	if err != nil || sha == "" {
+		return nil, false, err
+	}
+	return func() error {
+		return releaseLock(ctx, name, sha)
+	}, true, nil
+}
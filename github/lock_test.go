@@ -0,0 +1,181 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockBackend mimics the git data API: refs only move to commits descending from their current target unless forced
+type lockBackend struct {
+	lock    sync.Mutex
+	commits map[string]map[string]interface{}
+	ref     string
+	// beforeDelete, when set, is called before the reference is deleted
+	beforeDelete func()
+}
+
+func (b *lockBackend) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		commit := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&commit))
+		assert.Equal(t, emptyTreeSHA, commit["tree"])
+		sha := fmt.Sprintf("lock-%d", len(b.commits)+1)
+		b.commits[sha] = commit
+		fmt.Fprintf(w, `{"sha": %q, "message": %q}`, sha, commit["message"])
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/commits/", func(w http.ResponseWriter, r *http.Request) {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		sha := strings.TrimPrefix(r.URL.Path, "/repos/actions-go/toolkit/git/commits/")
+		fmt.Fprintf(w, `{"sha": %q, "message": %q}`, sha, b.commits[sha]["message"])
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/ref/locks/deploy", func(w http.ResponseWriter, r *http.Request) {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if b.ref == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"ref": "refs/locks/deploy", "object": {"sha": %q}}`, b.ref)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		body := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "refs/locks/deploy", body["ref"])
+		if b.ref != "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Reference already exists"}`)
+			return
+		}
+		b.ref = body["sha"]
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"ref": "refs/locks/deploy", "object": {"sha": %q}}`, b.ref)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/refs/locks/deploy", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && b.beforeDelete != nil {
+			b.beforeDelete()
+		}
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if r.Method == http.MethodDelete {
+			b.ref = ""
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, false, body["force"])
+		sha := body["sha"].(string)
+		parents, _ := b.commits[sha]["parents"].([]interface{})
+		if b.ref == "" || len(parents) != 1 || parents[0] != b.ref {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "Update is not a fast forward"}`)
+			return
+		}
+		b.ref = sha
+		fmt.Fprintf(w, `{"ref": "refs/locks/deploy", "object": {"sha": %q}}`, b.ref)
+	})
+	return mux
+}
+
+func TestAcquireLock(t *testing.T) {
+	backend := &lockBackend{commits: map[string]map[string]interface{}{}}
+	mux := http.NewServeMux()
+	mux.Handle("/", backend.handler(t))
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	ctx := context.Background()
+
+	release, acquired, err := AcquireLock(ctx, "deploy", 10*time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Equal(t, "lock-1", backend.ref)
+	message := backend.commits["lock-1"]["message"].(string)
+	assert.Contains(t, message, "lock deploy\n\nholder: ")
+	assert.Contains(t, message, "expires: "+now().Add(10*time.Minute).UTC().Format(time.RFC3339))
+
+	t.Run("concurrent runs do not acquire a held lock", func(t *testing.T) {
+		results := make(chan bool, 5)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, acquired, err := AcquireLock(ctx, "deploy", 10*time.Minute)
+				assert.NoError(t, err)
+				results <- acquired
+			}()
+		}
+		wg.Wait()
+		close(results)
+		for acquired := range results {
+			assert.False(t, acquired)
+		}
+		assert.Equal(t, "lock-1", backend.ref)
+	})
+
+	t.Run("a single run breaks an expired lock", func(t *testing.T) {
+		<-after(11 * time.Minute)
+		releases := make(chan func() error, 5)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release, acquired, err := AcquireLock(ctx, "deploy", 10*time.Minute)
+				assert.NoError(t, err)
+				if acquired {
+					releases <- release
+				}
+			}()
+		}
+		wg.Wait()
+		close(releases)
+		require.Len(t, releases, 1)
+		assert.NotEqual(t, "lock-1", backend.ref)
+
+		assert.Error(t, release(), "the expired lock was taken by another run")
+		assert.NotEqual(t, "", backend.ref)
+		assert.NoError(t, (<-releases)())
+		assert.Equal(t, "", backend.ref)
+	})
+
+	t.Run("released locks can be acquired again", func(t *testing.T) {
+		release, acquired, err := AcquireLock(ctx, "deploy", 10*time.Minute)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		held := backend.ref
+		backend.beforeDelete = func() {
+			defer func() { backend.beforeDelete = nil }()
+			released := backend.commits[backend.ref]
+			assert.Contains(t, released["message"], "release deploy\n\nholder: ")
+			assert.Equal(t, []interface{}{held}, released["parents"], "the release commit must descend from the held lock")
+			_, acquired, err := AcquireLock(ctx, "deploy", 10*time.Minute)
+			assert.NoError(t, err)
+			assert.False(t, acquired, "the lock must not be taken before being deleted")
+		}
+		assert.NoError(t, release())
+		assert.Equal(t, "", backend.ref)
+
+		release, acquired, err = AcquireLock(ctx, "deploy", 10*time.Minute)
+		require.NoError(t, err)
+		require.True(t, acquired)
+		assert.NoError(t, release())
+	})
+}
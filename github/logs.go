@@ -0,0 +1,49 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// DownloadRunLogs downloads and extracts the logs of a workflow run, one file per job step, for example build/1_Set up job.txt.
+// When runID is 0, the current run is used. GitHub only provides the logs once the run has completed and until they expire,
+// an ErrNotFound is returned outside of this window
+func DownloadRunLogs(ctx context.Context, runID int64) (map[string]RepositoryFile, error) {
+	if runID == 0 {
+		runID = RunID()
+	}
+	u, resp, err := GitHub.Actions.GetWorkflowRunLogs(ctx, Context.Repo.Owner, Context.Repo.Repo, runID, true)
+	if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+		return nil, &ErrNotFound{
+			Resource: fmt.Sprintf("logs of run %d", runID),
+			Err:      fmt.Errorf("logs are available once the run completes and until they expire: %v", err),
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs URL of run %d: %v", runID, PermissionError(resp, err))
+	}
+	core.Debugf("Downloading logs of run %d", runID)
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the download URL is signed and must not receive the token
+	logs, err := baseHTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download logs of run %d: %v", runID, err)
+	}
+	defer logs.Body.Close()
+	if logs.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download logs of run %d: unexpected code %d", runID, logs.StatusCode)
+	}
+	b, err := ioutil.ReadAll(logs.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download logs of run %d: %v", runID, err)
+	}
+	return readZip(bytes.NewReader(b), int64(len(b)), func(string) bool { return true }, 0, &DownloadOptions{})
+}
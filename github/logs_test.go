@@ -0,0 +1,51 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadRunLogs(t *testing.T) {
+	archive := bytes.NewBuffer(nil)
+	require.NoError(t, WriteZip(archive, map[string]RepositoryFile{
+		"build/1_Set up job.txt": {Data: []byte("Current runner version: '2.300.0'")},
+		"build/2_Run tests.txt":  {Data: []byte("ok  github.com/actions-go/toolkit/core")},
+	}))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/30433642/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", fmt.Sprintf("http://%s/signed/logs.zip", r.Host))
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/signed/logs.zip", func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(archive.Bytes())
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/1/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/runs/2/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+	defer mockEnv(map[string]string{"GITHUB_RUN_ID": "30433642"})()
+
+	files, err := DownloadRunLogs(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "ok  github.com/actions-go/toolkit/core", string(files["build/2_Run tests.txt"].Data))
+
+	for _, runID := range []int64{1, 2} {
+		_, err := DownloadRunLogs(context.Background(), runID)
+		if assert.IsType(t, &ErrNotFound{}, err) {
+			assert.Contains(t, err.Error(), fmt.Sprintf("logs of run %d", runID))
+		}
+	}
+}
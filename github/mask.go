@@ -0,0 +1,24 @@
+package github
+
+import (
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// MaskValue masks value from the workflow logs and from the messages this toolkit writes, for example a token derived
+// from a secret. GitHub masks lines individually, multi-line values are masked line by line
+func MaskValue(value string) {
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			core.SetSecret(line)
+		}
+	}
+}
+
+// MaskValues masks each of values, see MaskValue
+func MaskValues(values ...string) {
+	for _, v := range values {
+		MaskValue(v)
+	}
+}
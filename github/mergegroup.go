@@ -0,0 +1,40 @@
+package github
+
+import "github.com/google/go-github/v32/github"
+
+// MergeGroup is the group of pull requests a merge queue is testing before merging them
+type MergeGroup struct {
+	// HeadSHA is the commit of the temporary branch created by the merge queue, checks must be reported on it
+	HeadSHA string `json:"head_sha"`
+	HeadRef string `json:"head_ref"`
+	// BaseSHA is the commit of the target branch the pull requests are merged onto
+	BaseSHA    string             `json:"base_sha"`
+	BaseRef    string             `json:"base_ref"`
+	HeadCommit *github.HeadCommit `json:"head_commit,omitempty"`
+}
+
+// IsMergeQueue returns whether the workflow has been triggered by a merge queue
+func IsMergeQueue() bool {
+	return Context.EventName == "merge_group"
+}
+
+// MergeGroupEvent returns the merge group that triggered the workflow, nil for other events
+func MergeGroupEvent() *MergeGroup {
+	return Context.Payload.MergeGroup
+}
+
+// MergeGroupHeadSHA returns the commit of the merge group that triggered the workflow, empty for other events
+func MergeGroupHeadSHA() string {
+	if g := MergeGroupEvent(); g != nil {
+		return g.HeadSHA
+	}
+	return ""
+}
+
+// MergeGroupBaseSHA returns the commit of the target branch of the merge group that triggered the workflow, empty for other events
+func MergeGroupBaseSHA() string {
+	if g := MergeGroupEvent(); g != nil {
+		return g.BaseSHA
+	}
+	return ""
+}
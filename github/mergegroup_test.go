@@ -0,0 +1,34 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeGroup(t *testing.T) {
+	defer setEnv(map[string]string{
+		"GITHUB_EVENT_NAME": "merge_group",
+		"GITHUB_EVENT_PATH": "merge_group_event.json",
+		"GITHUB_SHA":        "d74fd518cf0410699c6b748924727686c1606d00",
+	})()
+	defer mockContext(ParseActionEnv())()
+
+	assert.True(t, IsMergeQueue())
+	if assert.NotNil(t, MergeGroupEvent()) {
+		assert.Equal(t, "refs/heads/main", MergeGroupEvent().BaseRef)
+		assert.Equal(t, "Merge pull request #12 from actions-go/feature", MergeGroupEvent().HeadCommit.GetMessage())
+	}
+	assert.Equal(t, "ec26c3e57ca3a959ca5aad62de7213c562f8c821", MergeGroupHeadSHA())
+	assert.Equal(t, "f95f852bd8fca8fcc58a9a2d6c842781e32a215e", MergeGroupBaseSHA())
+	assert.Equal(t, "ec26c3e57ca3a959ca5aad62de7213c562f8c821", HeadSHA())
+
+	t.Run("other events have no merge group", func(t *testing.T) {
+		Context = ActionContext{EventName: "push", SHA: "d74fd518cf0410699c6b748924727686c1606d00"}
+		assert.False(t, IsMergeQueue())
+		assert.Nil(t, MergeGroupEvent())
+		assert.Equal(t, "", MergeGroupHeadSHA())
+		assert.Equal(t, "", MergeGroupBaseSHA())
+		assert.Equal(t, "d74fd518cf0410699c6b748924727686c1606d00", HeadSHA())
+	})
+}
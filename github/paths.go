@@ -0,0 +1,79 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// workflowGlobToRegexp converts a workflow filter pattern to a regular expression.
+// Unlike in .gitignore files, ** matches any character, including slashes, wherever it appears
+func workflowGlobToRegexp(glob string) string {
+	parts := strings.Split(glob, "**")
+	s := globToRegexp(parts[0])
+	for _, part := range parts[1:] {
+		if strings.HasPrefix(part, "/") {
+			s += "(.*/)?"
+			part = part[1:]
+		} else {
+			s += ".*"
+		}
+		s += globToRegexp(part)
+	}
+	return "^" + s + "$"
+}
+
+type pathFilter struct {
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+func compilePathFilters(patterns []string) []pathFilter {
+	filters := []pathFilter{}
+	for _, p := range patterns {
+		f := pathFilter{}
+		if strings.HasPrefix(p, "!") {
+			f.negate = true
+			p = p[1:]
+		}
+		exp, err := regexp.Compile(workflowGlobToRegexp(p))
+		if err != nil {
+			core.Warningf("unable to compile path filter %s: %v", p, err)
+			continue
+		}
+		f.pattern = exp
+		filters = append(filters, f)
+	}
+	return filters
+}
+
+// matchPathFilters returns whether the path matches the filters, the last matching filter wins
+func matchPathFilters(filters []pathFilter, p string) bool {
+	matched := false
+	for _, f := range filters {
+		if f.pattern.MatchString(p) {
+			matched = !f.negate
+		}
+	}
+	return matched
+}
+
+// PathsFilter returns the changed paths that would trigger a workflow filtered with paths and paths-ignore,
+// typically to decide whether some work is needed based on ChangedFiles.
+// An empty include matches all paths, patterns prefixed with ! in include exclude paths matched by a previous pattern.
+// Paths matching exclude are never returned. Invalid patterns are reported as warnings and ignored
+func PathsFilter(changed []string, include, exclude []string) (matched []string) {
+	includes, excludes := compilePathFilters(include), compilePathFilters(exclude)
+	matched = []string{}
+	for _, p := range changed {
+		if len(include) > 0 && !matchPathFilters(includes, p) {
+			continue
+		}
+		if matchPathFilters(excludes, p) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	return matched
+}
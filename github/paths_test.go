@@ -0,0 +1,38 @@
+package github_test
+
+import (
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathsFilter(t *testing.T) {
+	changed := []string{
+		"README.md",
+		"docs/index.md",
+		"docs/api/client.md",
+		"src/main.js",
+		"src/lib/util.js",
+		"db/migrate-001.sql",
+		"db/old/migrate-000.sql",
+	}
+
+	assert.Equal(t, changed, github.PathsFilter(changed, nil, nil), "without filters, all paths match")
+
+	t.Run("paths includes matching files", func(t *testing.T) {
+		assert.Equal(t, []string{"src/main.js", "src/lib/util.js"}, github.PathsFilter(changed, []string{"**.js"}, nil))
+		assert.Equal(t, []string{"docs/index.md", "docs/api/client.md"}, github.PathsFilter(changed, []string{"docs/**"}, nil))
+		assert.Equal(t, []string{"README.md"}, github.PathsFilter(changed, []string{"*.md"}, nil))
+		assert.Equal(t, []string{"db/migrate-001.sql", "db/old/migrate-000.sql"}, github.PathsFilter(changed, []string{"**/migrate-*.sql"}, nil))
+	})
+	t.Run("negated paths exclude files matched by a previous pattern", func(t *testing.T) {
+		assert.Equal(t, []string{"docs/index.md"}, github.PathsFilter(changed, []string{"docs/**", "!docs/api/**"}, nil))
+		assert.Equal(t, []string{"docs/index.md", "docs/api/client.md"}, github.PathsFilter(changed, []string{"docs/**", "!docs/api/**", "docs/api/*.md"}, nil))
+	})
+	t.Run("paths-ignore excludes matching files", func(t *testing.T) {
+		assert.Equal(t, []string{"src/main.js", "src/lib/util.js", "db/migrate-001.sql", "db/old/migrate-000.sql"}, github.PathsFilter(changed, nil, []string{"**.md"}))
+		assert.Equal(t, []string{"src/main.js"}, github.PathsFilter(changed, []string{"src/**"}, []string{"src/lib/**"}))
+		assert.Empty(t, github.PathsFilter(changed, []string{"**.md"}, []string{"**"}))
+	})
+}
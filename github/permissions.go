@@ -0,0 +1,47 @@
+package github
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// acceptedPermissions parses the X-Accepted-GitHub-Permissions header, for example "contents=write; pull_requests=read"
+func acceptedPermissions(header string) []string {
+	permissions := []string{}
+	seen := map[string]bool{}
+	for _, p := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		parts := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		permission := strings.TrimSpace(parts[0]) + ": " + strings.TrimSpace(parts[1])
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// unauthorized inspects a failed response and returns the permissions the token lacks, when GitHub reports them
+func unauthorized(resp *github.Response, err error) (*ErrUnauthorized, bool) {
+	if err == nil || resp == nil || resp.Response == nil || resp.StatusCode != http.StatusForbidden {
+		return nil, false
+	}
+	permissions := acceptedPermissions(resp.Header.Get("X-Accepted-GitHub-Permissions"))
+	if len(permissions) == 0 {
+		return nil, false
+	}
+	return &ErrUnauthorized{RequiredPermissions: permissions, Err: err}, true
+}
+
+// PermissionError returns an ErrUnauthorized naming the required permissions when a call has been
+// rejected because the token lacks them. Other errors are returned unchanged
+func PermissionError(resp *github.Response, err error) error {
+	if e, ok := unauthorized(resp, err); ok {
+		return e
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accepted-GitHub-Permissions", "issues=write; pull_requests=write")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource not accessible by personal access token"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/other/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Forbidden"}`)
+	})
+	defer mockGitHub(mux)()
+
+	err := RetryRateLimited(context.Background(), createComment(context.Background()))
+	if assert.IsType(t, &ErrUnauthorized{}, err) {
+		assert.Equal(t, []string{"issues: write", "pull_requests: write"}, err.(*ErrUnauthorized).RequiredPermissions)
+		assert.Contains(t, err.Error(), "token needs `issues: write, pull_requests: write`")
+	}
+
+	t.Run("without the header, the original error is returned", func(t *testing.T) {
+		_, resp, err := GitHub.Issues.CreateComment(context.Background(), "actions-go", "other", 1, nil)
+		assert.Error(t, err)
+		assert.Equal(t, err, PermissionError(resp, err))
+	})
+}
@@ -0,0 +1,33 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// branchNotProtected returns whether reading the protection of a branch failed because it is not protected,
+// other not found errors are returned for missing branches or repositories, or missing permissions
+func branchNotProtected(err error) bool {
+	e, ok := err.(*github.ErrorResponse)
+	return ok && e.Response != nil && e.Response.StatusCode == http.StatusNotFound && e.Message == "Branch not protected"
+}
+
+// RequiredChecks returns the status check contexts branch protection requires to pass before merging to branch of the repository running the workflow.
+// An empty slice is returned when the branch is not protected. Reading branch protection requires the `administration: read` permission
+func RequiredChecks(ctx context.Context, branch string) ([]string, error) {
+	protection, resp, err := GitHub.Repositories.GetBranchProtection(ctx, Context.Repo.Owner, Context.Repo.Repo, branch)
+	if branchNotProtected(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protection of branch %s: %v", branch, PermissionError(resp, err))
+	}
+	checks := protection.GetRequiredStatusChecks()
+	if checks == nil {
+		return []string{}, nil
+	}
+	return append([]string{}, checks.Contexts...), nil
+}
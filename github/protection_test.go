@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"required_status_checks": {"strict": true, "contexts": ["build", "lint"]}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/branches/release/protection", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"enforce_admins": {"enabled": true}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/branches/feature/protection", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Branch not protected"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/branches/missing/protection", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	checks, err := RequiredChecks(context.Background(), "main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build", "lint"}, checks)
+
+	for _, branch := range []string{"release", "feature"} {
+		checks, err = RequiredChecks(context.Background(), branch)
+		assert.NoError(t, err, branch)
+		assert.Equal(t, []string{}, checks, branch)
+	}
+
+	_, err = RequiredChecks(context.Background(), "missing")
+	assert.Error(t, err, "only unprotected branches are reported without checks")
+}
@@ -0,0 +1,185 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// mergeableTimeout is how long PullRequestMergeable waits for GitHub to compute the mergeability
+const mergeableTimeout = 2 * time.Minute
+
+// pullRequestNumber returns the number of the pull request that triggered the workflow, including from comments on pull requests
+func pullRequestNumber() (int, error) {
+	if n := Context.Payload.PullRequest.GetNumber(); n != 0 {
+		return n, nil
+	}
+	if Context.Payload.Issue.IsPullRequest() {
+		return Context.Payload.Issue.GetNumber(), nil
+	}
+	return 0, fmt.Errorf("the workflow has not been triggered by a pull request")
+}
+
+// PullRequestMergeable returns whether the pull request that triggered the workflow can be merged and its merge state,
+// for example clean, blocked, behind or dirty. GitHub computes the mergeability asynchronously,
+// the pull request is polled until it is known or a 2 minutes timeout elapses
+func PullRequestMergeable(ctx context.Context) (mergeable bool, state string, err error) {
+	number, err := pullRequestNumber()
+	if err != nil {
+		return false, "", err
+	}
+	done, err := poll(ctx, mergeableTimeout, func() (bool, time.Duration, error) {
+		pr, resp, err := GitHub.PullRequests.Get(ctx, Context.Repo.Owner, Context.Repo.Repo, number)
+		if limit, ok := rateLimited(resp, err); ok {
+			return false, limit.RetryAfter, nil
+		}
+		if err != nil {
+			return false, 0, fmt.Errorf("failed to get pull request %d: %v", number, err)
+		}
+		if pr.Mergeable == nil {
+			return false, 0, nil
+		}
+		mergeable, state = pr.GetMergeable(), pr.GetMergeableState()
+		return true, 0, nil
+	})
+	if err != nil {
+		return false, "", err
+	}
+	if !done {
+		return false, "", fmt.Errorf("timed out after %v waiting for GitHub to compute the mergeability of pull request %d", mergeableTimeout, number)
+	}
+	return mergeable, state, nil
+}
+
+// mergeMethods are the methods MergePullRequest accepts
+var mergeMethods = map[string]bool{"merge": true, "squash": true, "rebase": true}
+
+// MergeOption customises the merge of pull requests by MergePullRequest
+type MergeOption func(*github.PullRequestOptions)
+
+// WithExpectedHeadSHA only merges the pull request when its head is sha, to avoid merging commits that were not verified
+func WithExpectedHeadSHA(sha string) MergeOption {
+	return func(o *github.PullRequestOptions) {
+		o.SHA = sha
+	}
+}
+
+// MergePullRequest merges the pull request that triggered the workflow with method, one of merge, squash or rebase.
+// Empty commit title and message keep the GitHub defaults.
+// An ErrNotMergeable is returned when GitHub refuses the merge and an ErrHeadChanged when the head is not the one expected by WithExpectedHeadSHA
+func MergePullRequest(ctx context.Context, method string, commitTitle, commitMessage string, options ...MergeOption) (*github.PullRequestMergeResult, error) {
+	if !mergeMethods[method] {
+		return nil, fmt.Errorf("invalid merge method %s, it must be one of merge, squash or rebase", method)
+	}
+	number, err := pullRequestNumber()
+	if err != nil {
+		return nil, err
+	}
+	opts := &github.PullRequestOptions{CommitTitle: commitTitle, MergeMethod: method}
+	for _, option := range options {
+		option(opts)
+	}
+	var result *github.PullRequestMergeResult
+	var resp *github.Response
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		var err error
+		result, resp, err = GitHub.PullRequests.Merge(ctx, Context.Repo.Owner, Context.Repo.Repo, number, commitMessage, opts)
+		return resp, err
+	})
+	if resp != nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		return nil, &ErrNotMergeable{Number: number, Err: err}
+	}
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		return nil, &ErrHeadChanged{Number: number, ExpectedSHA: opts.SHA, Err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pull request %d: %v", number, err)
+	}
+	return result, nil
+}
+
+// PullRequestOptions describes the pull request to open with CreatePullRequest
+type PullRequestOptions struct {
+	// Owner and Repo default to the repository running the workflow
+	Owner string
+	Repo  string
+	// Head is the branch holding the changes, prefix it with the owner and a colon for branches of forks
+	Head  string
+	Base  string
+	Title string
+	Body  string
+	Draft bool
+	// MaintainerCanModify allows maintainers of the base repository to push to the head branch
+	MaintainerCanModify bool
+	// Upsert updates the title and body of the open pull request from Head to Base, when there is one, instead of failing
+	Upsert bool
+}
+
+// findOpenPullRequest returns the open pull request from head to base, nil when there is none
+func findOpenPullRequest(ctx context.Context, owner, repo, head, base string) (*github.PullRequest, error) {
+	if !strings.Contains(head, ":") {
+		head = owner + ":" + head
+	}
+	prs, _, err := GitHub.PullRequests.List(ctx, owner, repo, &github.PullRequestListOptions{State: "open", Head: head, Base: base})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests from %s to %s: %v", head, base, err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	return prs[0], nil
+}
+
+// CreatePullRequest opens a pull request and returns it. With Upsert, when a pull request from the same head to the same
+// base is already open, its title and body are updated instead and the existing pull request is returned
+func CreatePullRequest(ctx context.Context, opts PullRequestOptions) (*github.PullRequest, error) {
+	if opts.Owner == "" {
+		opts.Owner = Context.Repo.Owner
+	}
+	if opts.Repo == "" {
+		opts.Repo = Context.Repo.Repo
+	}
+	var pr *github.PullRequest
+	var resp *github.Response
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		var err error
+		pr, resp, err = GitHub.PullRequests.Create(ctx, opts.Owner, opts.Repo, &github.NewPullRequest{
+			Title:               github.String(opts.Title),
+			Head:                github.String(opts.Head),
+			Base:                github.String(opts.Base),
+			Body:                github.String(opts.Body),
+			Draft:               github.Bool(opts.Draft),
+			MaintainerCanModify: github.Bool(opts.MaintainerCanModify),
+		})
+		return resp, err
+	})
+	if err == nil {
+		return pr, nil
+	}
+	if !opts.Upsert || resp == nil || resp.StatusCode != http.StatusUnprocessableEntity {
+		return nil, fmt.Errorf("failed to create pull request from %s to %s: %v", opts.Head, opts.Base, err)
+	}
+	existing, findErr := findOpenPullRequest(ctx, opts.Owner, opts.Repo, opts.Head, opts.Base)
+	if findErr != nil {
+		return nil, findErr
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("failed to create pull request from %s to %s: %v", opts.Head, opts.Base, err)
+	}
+	err = RetryRateLimited(ctx, func() (*github.Response, error) {
+		var err error
+		pr, resp, err = GitHub.PullRequests.Edit(ctx, opts.Owner, opts.Repo, existing.GetNumber(), &github.PullRequest{
+			Title: github.String(opts.Title),
+			Body:  github.String(opts.Body),
+		})
+		return resp, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request %d: %v", existing.GetNumber(), err)
+	}
+	return pr, nil
+}
@@ -0,0 +1,166 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestMergeable(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/12", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls == 1 {
+			fmt.Fprint(w, `{"number": 12, "mergeable": null, "mergeable_state": "unknown"}`)
+			return
+		}
+		fmt.Fprint(w, `{"number": 12, "mergeable": true, "mergeable_state": "clean"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/13", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 13, "mergeable": null, "mergeable_state": "unknown"}`)
+	})
+	defer mockGitHub(mux)()
+	repo := ActionRepo{Owner: "actions-go", Repo: "toolkit"}
+	defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{PullRequest: &github.PullRequest{Number: github.Int(12)}}})()
+
+	mergeable, state, err := PullRequestMergeable(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, mergeable)
+	assert.Equal(t, "clean", state)
+	assert.Equal(t, 2, polls)
+	assert.Equal(t, []time.Duration{5 * time.Second}, waits)
+
+	t.Run("comments on pull requests are supported", func(t *testing.T) {
+		Context.Payload = WebhookPayload{Issue: &github.Issue{Number: github.Int(12), PullRequestLinks: &github.PullRequestLinks{}}}
+		_, state, err := PullRequestMergeable(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "clean", state)
+	})
+
+	t.Run("an unknown mergeability times out", func(t *testing.T) {
+		Context.Payload = WebhookPayload{PullRequest: &github.PullRequest{Number: github.Int(13)}}
+		_, _, err := PullRequestMergeable(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("other events are rejected", func(t *testing.T) {
+		Context.Payload = WebhookPayload{Issue: &github.Issue{Number: github.Int(12)}}
+		_, _, err := PullRequestMergeable(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestMergePullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/12/merge", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		body := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body["sha"] {
+		case "stale":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message": "Head branch was modified. Review and try the merge again."}`)
+		case "blocked":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			fmt.Fprint(w, `{"message": "Required status check \"build\" is failing."}`)
+		default:
+			assert.Equal(t, "squash", body["merge_method"])
+			assert.Equal(t, "Release v1.0.0 (#12)", body["commit_title"])
+			assert.Equal(t, "Details", body["commit_message"])
+			fmt.Fprint(w, `{"sha": "d74fd51", "merged": true, "message": "Pull Request successfully merged"}`)
+		}
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, Payload: WebhookPayload{PullRequest: &github.PullRequest{Number: github.Int(12)}}})()
+
+	result, err := MergePullRequest(context.Background(), "squash", "Release v1.0.0 (#12)", "Details", WithExpectedHeadSHA("abc"))
+	require.NoError(t, err)
+	assert.True(t, result.GetMerged())
+	assert.Equal(t, "d74fd51", result.GetSHA())
+
+	_, err = MergePullRequest(context.Background(), "squash", "", "", WithExpectedHeadSHA("stale"))
+	if assert.IsType(t, &ErrHeadChanged{}, err) {
+		assert.Equal(t, "stale", err.(*ErrHeadChanged).ExpectedSHA)
+	}
+
+	_, err = MergePullRequest(context.Background(), "merge", "", "", WithExpectedHeadSHA("blocked"))
+	assert.IsType(t, &ErrNotMergeable{}, err)
+
+	_, err = MergePullRequest(context.Background(), "fast-forward", "", "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid merge method")
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	open := map[string]*github.PullRequest{}
+	edited := []int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			assert.Equal(t, "open", r.URL.Query().Get("state"))
+			assert.Equal(t, "main", r.URL.Query().Get("base"))
+			prs := []*github.PullRequest{}
+			if pr, ok := open[r.URL.Query().Get("head")]; ok {
+				prs = append(prs, pr)
+			}
+			json.NewEncoder(w).Encode(prs)
+			return
+		}
+		pr := &github.NewPullRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(pr))
+		if _, ok := open["actions-go:"+pr.GetHead()]; ok {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprintf(w, `{"message": "Validation Failed", "errors": [{"resource": "PullRequest", "code": "custom", "message": "A pull request already exists for actions-go:%s."}]}`, pr.GetHead())
+			return
+		}
+		assert.True(t, pr.GetDraft())
+		assert.True(t, pr.GetMaintainerCanModify())
+		created := &github.PullRequest{Number: github.Int(len(open) + 5), Title: pr.Title, Body: pr.Body}
+		open["actions-go:"+pr.GetHead()] = created
+		json.NewEncoder(w).Encode(created)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/5", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		edited = append(edited, 5)
+		pr := &github.PullRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(pr))
+		existing := open["actions-go:bump-deps"]
+		existing.Title, existing.Body = pr.Title, pr.Body
+		json.NewEncoder(w).Encode(existing)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	opts := PullRequestOptions{Head: "bump-deps", Base: "main", Title: "Bump dependencies", Body: "3 updates", Draft: true, MaintainerCanModify: true, Upsert: true}
+	pr, err := CreatePullRequest(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 5, pr.GetNumber())
+	assert.Empty(t, edited)
+
+	opts.Body = "4 updates"
+	pr, err = CreatePullRequest(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, 5, pr.GetNumber(), "the open pull request is returned")
+	assert.Equal(t, "4 updates", pr.GetBody())
+	assert.Equal(t, []int{5}, edited)
+
+	t.Run("without upsert existing pull requests are reported", func(t *testing.T) {
+		opts.Upsert = false
+		_, err := CreatePullRequest(context.Background(), opts)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "A pull request already exists")
+		}
+		assert.Equal(t, []int{5}, edited)
+	})
+}
@@ -0,0 +1,145 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/google/go-github/v32/github"
+)
+
+// rateLimited inspects a failed response and returns the rate limit it reports, if any.
+// Secondary rate limits come with a Retry-After header while primary ones report their reset time
+func rateLimited(resp *github.Response, err error) (*ErrRateLimited, bool) {
+	if err == nil || resp == nil || resp.Response == nil {
+		return nil, false
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, false
+	}
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		seconds, parseErr := strconv.ParseInt(v, 10, 64)
+		if parseErr == nil {
+			return &ErrRateLimited{RetryAfter: time.Duration(seconds) * time.Second, Secondary: true, Err: err}, true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, parseErr := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if parseErr == nil {
+			return &ErrRateLimited{RetryAfter: time.Unix(reset, 0).Sub(now()), Err: err}, true
+		}
+	}
+	return nil, false
+}
+
+// RetryRateLimited runs a write call to the GitHub API and retries it once, after waiting as requested,
+// when it hits a secondary rate limit. Primary rate limits are not retried as they may last up to an hour.
+// An ErrRateLimited is returned when the call is still rate limited and an ErrUnauthorized when the token lacks permissions
+func RetryRateLimited(ctx context.Context, call func() (*github.Response, error)) error {
+	resp, err := call()
+	limit, ok := rateLimited(resp, err)
+	if !ok {
+		return PermissionError(resp, err)
+	}
+	if !limit.Secondary {
+		return limit
+	}
+	if err := sleep(ctx, limit.RetryAfter); err != nil {
+		return err
+	}
+	resp, err = call()
+	if limit, ok := rateLimited(resp, err); ok {
+		return limit
+	}
+	return PermissionError(resp, err)
+}
+
+// RateLimits reports the remaining budget of the token for each API
+type RateLimits struct {
+	Core    *github.Rate `json:"core"`
+	Search  *github.Rate `json:"search"`
+	GraphQL *github.Rate `json:"graphql"`
+}
+
+// RateLimit returns the current rate limits of the token. Checking rate limits does not count against them
+func RateLimit(ctx context.Context) (*RateLimits, error) {
+	req, err := GitHub.NewRequest(http.MethodGet, "rate_limit", nil)
+	if err != nil {
+		return nil, err
+	}
+	response := &struct {
+		Resources *RateLimits `json:"resources"`
+	}{}
+	if _, err := GitHub.Do(ctx, req, response); err != nil {
+		return nil, fmt.Errorf("failed to get rate limits: %v", err)
+	}
+	if response.Resources == nil {
+		return &RateLimits{}, nil
+	}
+	return response.Resources, nil
+}
+
+func (l *RateLimits) resource(name string) *github.Rate {
+	switch name {
+	case "search":
+		return l.Search
+	case "graphql":
+		return l.GraphQL
+	default:
+		return l.Core
+	}
+}
+
+type budgetOptions struct {
+	resource string
+	maxWait  time.Duration
+}
+
+// BudgetOption customises the budget checked by EnsureBudget
+type BudgetOption func(*budgetOptions)
+
+// WithBudgetResource checks the budget of another API than the REST one: search or graphql
+func WithBudgetResource(resource string) BudgetOption {
+	return func(o *budgetOptions) {
+		o.resource = resource
+	}
+}
+
+// WithBudgetWait waits for the rate limit to reset when the budget is too low, as long as it resets within maxWait
+func WithBudgetWait(maxWait time.Duration) BudgetOption {
+	return func(o *budgetOptions) {
+		o.maxWait = maxWait
+	}
+}
+
+// EnsureBudget checks at least need requests are left before starting a batch of calls to the REST API, or the resource set with WithBudgetResource.
+// By default, an ErrRateLimited is returned right away when the budget is too low so that actions fail fast rather than midway
+func EnsureBudget(ctx context.Context, need int, options ...BudgetOption) error {
+	o := budgetOptions{resource: "core"}
+	for _, option := range options {
+		option(&o)
+	}
+	limits, err := RateLimit(ctx)
+	if err != nil {
+		return err
+	}
+	rate := limits.resource(o.resource)
+	if rate == nil || rate.Remaining >= need {
+		return nil
+	}
+	limit := &ErrRateLimited{
+		RetryAfter: rate.Reset.Sub(now()),
+		Err:        fmt.Errorf("%d %s requests left out of %d, %d needed", rate.Remaining, o.resource, rate.Limit, need),
+	}
+	if limit.RetryAfter > o.maxWait {
+		return limit
+	}
+	core.Infof("waiting %v for the %s rate limit to reset", limit.RetryAfter, o.resource)
+	if err := sleep(ctx, limit.RetryAfter); err != nil {
+		return err
+	}
+	return nil
+}
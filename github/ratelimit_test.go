@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func createComment(ctx context.Context) func() (*github.Response, error) {
+	return func() (*github.Response, error) {
+		_, resp, err := GitHub.Issues.CreateComment(ctx, "actions-go", "toolkit", 1, &github.IssueComment{Body: github.String("hello")})
+		return resp, err
+	}
+}
+
+func rateLimitedHandler(failures int, headers map[string]string) (http.HandlerFunc, *int) {
+	calls := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id": 1, "body": "hello"}`)
+	}, &calls
+}
+
+func TestRetryRateLimitedSecondary(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+
+	t.Run("the call is retried once after the requested delay", func(t *testing.T) {
+		handler, calls := rateLimitedHandler(1, map[string]string{"Retry-After": "30"})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", handler)
+		defer mockGitHub(mux)()
+
+		waits = waits[:0]
+		assert.NoError(t, RetryRateLimited(context.Background(), createComment(context.Background())))
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, []time.Duration{30 * time.Second}, waits)
+	})
+
+	t.Run("when retries are exhausted, the rate limit is surfaced", func(t *testing.T) {
+		handler, calls := rateLimitedHandler(2, map[string]string{"Retry-After": "10"})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", handler)
+		defer mockGitHub(mux)()
+
+		err := RetryRateLimited(context.Background(), createComment(context.Background()))
+		if assert.IsType(t, &ErrRateLimited{}, err) {
+			assert.True(t, err.(*ErrRateLimited).Secondary)
+			assert.Equal(t, 10*time.Second, err.(*ErrRateLimited).RetryAfter)
+		}
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("waiting is cancelled with the context", func(t *testing.T) {
+		handler, calls := rateLimitedHandler(1, map[string]string{"Retry-After": "10"})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", handler)
+		defer mockGitHub(mux)()
+		after = func(time.Duration) <-chan time.Time { return nil }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- RetryRateLimited(ctx, createComment(context.Background())) }()
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestRetryRateLimitedPrimary(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	reset := time.Now().Add(10 * time.Minute).Unix()
+	handler, calls := rateLimitedHandler(1, map[string]string{
+		"X-RateLimit-Limit":     "5000",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", handler)
+	defer mockGitHub(mux)()
+
+	err := RetryRateLimited(context.Background(), createComment(context.Background()))
+	if assert.IsType(t, &ErrRateLimited{}, err) {
+		assert.False(t, err.(*ErrRateLimited).Secondary)
+		assert.InDelta(t, float64(10*time.Minute), float64(err.(*ErrRateLimited).RetryAfter), float64(5*time.Second))
+	}
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, waits)
+}
+
+func TestRetryRateLimitedOtherErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer mockGitHub(mux)()
+	err := RetryRateLimited(context.Background(), createComment(context.Background()))
+	assert.Error(t, err)
+	assert.IsType(t, &github.ErrorResponse{}, err)
+}
+
+func TestEnsureBudget(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	reset := now().Add(10 * time.Minute).Unix()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"resources": {
+			"core": {"limit": 5000, "remaining": 120, "reset": %d},
+			"search": {"limit": 30, "remaining": 30, "reset": %d},
+			"graphql": {"limit": 5000, "remaining": 4000, "reset": %d}
+		}}`, reset, reset, reset)
+	})
+	defer mockGitHub(mux)()
+
+	limits, err := RateLimit(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 120, limits.Core.Remaining)
+	assert.Equal(t, 4000, limits.GraphQL.Remaining)
+
+	assert.NoError(t, EnsureBudget(context.Background(), 100))
+	assert.NoError(t, EnsureBudget(context.Background(), 1000, WithBudgetResource("graphql")))
+
+	err = EnsureBudget(context.Background(), 1000)
+	if assert.IsType(t, &ErrRateLimited{}, err) {
+		assert.Contains(t, err.Error(), "120 core requests left out of 5000, 1000 needed")
+		assert.InDelta(t, float64(10*time.Minute), float64(err.(*ErrRateLimited).RetryAfter), float64(time.Second))
+	}
+	assert.IsType(t, &ErrRateLimited{}, EnsureBudget(context.Background(), 1000, WithBudgetWait(time.Minute)), "the reset is too far to wait for")
+	assert.Empty(t, waits)
+
+	assert.NoError(t, EnsureBudget(context.Background(), 1000, WithBudgetWait(time.Hour)))
+	if assert.Len(t, waits, 1) {
+		assert.InDelta(t, float64(10*time.Minute), float64(waits[0]), float64(time.Second))
+	}
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// Reactions are the reactions GitHub allows on comments
+var Reactions = []string{"+1", "-1", "laugh", "hooray", "confused", "heart", "rocket", "eyes"}
+
+// AddReaction reacts to the comment that triggered an issue_comment or pull_request_review_comment event,
+// typically with eyes to acknowledge a command. reaction must be one of Reactions
+func AddReaction(ctx context.Context, reaction string) error {
+	valid := false
+	for _, r := range Reactions {
+		valid = valid || r == reaction
+	}
+	if !valid {
+		return fmt.Errorf("invalid reaction %q, must be one of %s", reaction, strings.Join(Reactions, ", "))
+	}
+	id := Context.Payload.Comment.GetID()
+	if id == 0 {
+		return fmt.Errorf("unable to react to the comment: event %s has no comment", Context.EventName)
+	}
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		if Context.EventName == "pull_request_review_comment" {
+			_, resp, err := GitHub.Reactions.CreatePullRequestCommentReaction(ctx, Context.Repo.Owner, Context.Repo.Repo, id, reaction)
+			return resp, err
+		}
+		_, resp, err := GitHub.Reactions.CreateIssueCommentReaction(ctx, Context.Repo.Owner, Context.Repo.Repo, id, reaction)
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add reaction %s to comment %d: %v", reaction, id, err)
+	}
+	return nil
+}
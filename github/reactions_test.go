@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddReaction(t *testing.T) {
+	reactions := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/comments/1234567/reactions", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body := map[string]string{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		reactions = append(reactions, body["content"])
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": 1, "content": %q}`, body["content"])
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/pulls/comments/1234567/reactions", func(w http.ResponseWriter, r *http.Request) {
+		reactions = append(reactions, "pull")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 2, "content": "eyes"}`)
+	})
+	defer mockGitHub(mux)()
+
+	defer setEnv(map[string]string{
+		"GITHUB_EVENT_NAME": "issue_comment",
+		"GITHUB_EVENT_PATH": "issue_comment_event.json",
+		"GITHUB_REPOSITORY": "actions-go/toolkit",
+	})()
+	defer mockContext(ParseActionEnv())()
+
+	assert.NoError(t, AddReaction(context.Background(), "eyes"))
+	assert.Error(t, AddReaction(context.Background(), "thumbsup"))
+	assert.Equal(t, []string{"eyes"}, reactions)
+
+	Context.EventName = "pull_request_review_comment"
+	assert.NoError(t, AddReaction(context.Background(), "eyes"))
+	assert.Equal(t, []string{"eyes", "pull"}, reactions)
+
+	Context.EventName = "push"
+	Context.Payload.Comment = nil
+	assert.Error(t, AddReaction(context.Background(), "eyes"))
+}
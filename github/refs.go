@@ -0,0 +1,181 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	// RefKindBranch is the kind of refs pointing to a branch
+	RefKindBranch = "branch"
+	// RefKindTag is the kind of refs pointing to a tag
+	RefKindTag = "tag"
+	// RefKindPullRequest is the kind of refs pointing to a pull request
+	RefKindPullRequest = "pull"
+	// RefKindCommit is the kind of revisions resolved from a commit SHA
+	RefKindCommit = "commit"
+)
+
+// shaLike matches revisions that may be abbreviated commit SHAs
+var shaLike = regexp.MustCompile("^[0-9a-fA-F]{4,40}$")
+
+// Ref is a git reference split into its kind and short name
+type Ref struct {
+	// Kind is one of RefKindBranch, RefKindTag, RefKindPullRequest or empty when the ref is not recognised
+	Kind string
+	// Name is the short name of the ref, for example main for refs/heads/main or 12/merge for refs/pull/12/merge
+	Name string
+}
+
+// ParseRef parses the ref that triggered the workflow, GITHUB_REF
+func ParseRef() Ref {
+	return parseRef(Context.Ref)
+}
+
+func parseRef(ref string) Ref {
+	for prefix, kind := range map[string]string{
+		"refs/heads/": RefKindBranch,
+		"refs/tags/":  RefKindTag,
+		"refs/pull/":  RefKindPullRequest,
+	} {
+		if strings.HasPrefix(ref, prefix) {
+			return Ref{Kind: kind, Name: strings.TrimPrefix(ref, prefix)}
+		}
+	}
+	return Ref{Name: ref}
+}
+
+// ListBranches returns the name of all branches of a repository, sorted alphabetically
+func ListBranches(ctx context.Context, owner, repo string) ([]string, error) {
+	names := []string{}
+	opts := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		branches, resp, err := GitHub.Repositories.ListBranches(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches of %s/%s: %v", owner, repo, err)
+		}
+		for _, b := range branches {
+			names = append(names, b.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListTags returns the name of all tags of a repository, sorted alphabetically
+func ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	names := []string{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		tags, resp, err := GitHub.Repositories.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s/%s: %v", owner, repo, err)
+		}
+		for _, t := range tags {
+			names = append(names, t.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// latestSemver returns the highest semantic version amongst tags, tags that are not semantic versions are ignored
+func latestSemver(tags []string, includePrereleases bool) (string, bool) {
+	versions := semver.Collection{}
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil {
+			continue
+		}
+		if v.Prerelease() != "" && !includePrereleases {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	if len(versions) == 0 {
+		return "", false
+	}
+	sort.Sort(versions)
+	return versions[len(versions)-1].Original(), true
+}
+
+// LatestTag returns the tag of a repository with the highest semantic version, with or without a leading v.
+// Pre-releases are only considered when includePrereleases is set
+func LatestTag(ctx context.Context, owner, repo string, includePrereleases bool) (string, error) {
+	tags, err := ListTags(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	latest, ok := latestSemver(tags, includePrereleases)
+	if !ok {
+		return "", fmt.Errorf("no semantic version tag found in %s/%s", owner, repo)
+	}
+	return latest, nil
+}
+
+// resolveRef returns the commit a ref points to, dereferencing annotated tags. found is false when the ref does not exist
+func resolveRef(ctx context.Context, owner, repo, ref string) (sha string, found bool, err error) {
+	r, resp, err := GitHub.Git.GetRef(ctx, owner, repo, ref)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get ref %s of %s/%s: %v", ref, owner, repo, err)
+	}
+	object := r.GetObject()
+	if object.GetType() == "tag" {
+		tag, _, err := GitHub.Git.GetTag(ctx, owner, repo, object.GetSHA())
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get tag %s of %s/%s: %v", ref, owner, repo, err)
+		}
+		object = tag.GetObject()
+	}
+	return object.GetSHA(), true, nil
+}
+
+// ResolveRevision returns the full commit SHA of rev in a repository and the kind of revision it matched: RefKindBranch,
+// RefKindTag or RefKindCommit. Branches take precedence over tags, which take precedence over commit SHAs, possibly abbreviated.
+// An ErrNotFound is returned when no revision matches
+func ResolveRevision(ctx context.Context, owner, repo, rev string) (fullSHA string, kind string, err error) {
+	for _, candidate := range []struct{ prefix, kind string }{
+		{"heads/", RefKindBranch},
+		{"tags/", RefKindTag},
+	} {
+		sha, found, err := resolveRef(ctx, owner, repo, candidate.prefix+rev)
+		if err != nil {
+			return "", "", err
+		}
+		if found {
+			return sha, candidate.kind, nil
+		}
+	}
+	if !shaLike.MatchString(rev) {
+		return "", "", &ErrNotFound{Resource: fmt.Sprintf("revision %s in %s/%s", rev, owner, repo), Err: fmt.Errorf("no branch nor tag matches")}
+	}
+	commit, resp, err := GitHub.Repositories.GetCommit(ctx, owner, repo, rev)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", "", &ErrNotFound{Resource: fmt.Sprintf("revision %s in %s/%s", rev, owner, repo), Err: err}
+	}
+	if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+		return "", "", fmt.Errorf("revision %s of %s/%s is ambiguous or invalid, use a longer SHA: %v", rev, owner, repo, err)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get commit %s of %s/%s: %v", rev, owner, repo, err)
+	}
+	return commit.GetSHA(), RefKindCommit, nil
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListBranches(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/branches", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "feature"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+		fmt.Fprint(w, `[{"name": "master"}, {"name": "develop"}]`)
+	})
+	defer mockGitHub(mux)()
+
+	branches, err := ListBranches(context.Background(), "actions-go", "toolkit")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"develop", "feature", "master"}, branches)
+
+	_, err = ListBranches(context.Background(), "actions-go", "missing")
+	assert.Error(t, err)
+}
+
+func TestLatestTag(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "v1.2.0"}, {"name": "v1.10.0"}, {"name": "1.9.3"}, {"name": "v2.0.0-rc.1"}, {"name": "latest"}]`)
+	})
+	mux.HandleFunc("/repos/actions-go/no-semver/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "latest"}]`)
+	})
+	defer mockGitHub(mux)()
+
+	tags, err := ListTags(context.Background(), "actions-go", "toolkit")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.9.3", "latest", "v1.10.0", "v1.2.0", "v2.0.0-rc.1"}, tags)
+
+	latest, err := LatestTag(context.Background(), "actions-go", "toolkit", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10.0", latest)
+
+	latest, err = LatestTag(context.Background(), "actions-go", "toolkit", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0-rc.1", latest)
+
+	_, err = LatestTag(context.Background(), "actions-go", "no-semver", true)
+	assert.Error(t, err)
+}
+
+func TestLatestSemverPrereleasePrecedence(t *testing.T) {
+	latest, ok := latestSemver([]string{"v1.0.0-rc.1", "v1.0.0-beta.2", "v1.0.0-rc.2"}, true)
+	assert.True(t, ok)
+	assert.Equal(t, "v1.0.0-rc.2", latest)
+	latest, _ = latestSemver([]string{"v1.0.0-rc.1", "v1.0.0"}, true)
+	assert.Equal(t, "v1.0.0", latest)
+	_, ok = latestSemver([]string{"v1.0.0-rc.1"}, false)
+	assert.False(t, ok)
+}
+
+func TestResolveRevision(t *testing.T) {
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/git/ref/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/actions-go/toolkit/git/ref/heads/main":
+			fmt.Fprint(w, `{"ref": "refs/heads/main", "object": {"type": "commit", "sha": "d74fd518cf0410699c6b748924727686c1606d00"}}`)
+		case "/repos/actions-go/toolkit/git/ref/tags/v1":
+			fmt.Fprint(w, `{"ref": "refs/tags/v1", "object": {"type": "tag", "sha": "0fa1c8f3c5fd9ab7f38b4f3b3e5e5fb8f00d1a37"}}`)
+		default:
+			notFound(w, r)
+		}
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/git/tags/0fa1c8f3c5fd9ab7f38b4f3b3e5e5fb8f00d1a37", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag": "v1", "object": {"type": "commit", "sha": "9e47c46c4433b1c2e1f5fd5a1f6c6a8b7a4b2c3d"}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/actions-go/toolkit/commits/d74fd51":
+			fmt.Fprint(w, `{"sha": "d74fd518cf0410699c6b748924727686c1606d00"}`)
+		case "/repos/actions-go/toolkit/commits/abcd":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "No commit found for SHA: abcd"}`)
+		default:
+			notFound(w, r)
+		}
+	})
+	defer mockGitHub(mux)()
+
+	for rev, expected := range map[string]struct{ sha, kind string }{
+		"main":    {"d74fd518cf0410699c6b748924727686c1606d00", RefKindBranch},
+		"v1":      {"9e47c46c4433b1c2e1f5fd5a1f6c6a8b7a4b2c3d", RefKindTag},
+		"d74fd51": {"d74fd518cf0410699c6b748924727686c1606d00", RefKindCommit},
+	} {
+		sha, kind, err := ResolveRevision(context.Background(), "actions-go", "toolkit", rev)
+		assert.NoError(t, err, rev)
+		assert.Equal(t, expected.sha, sha, rev)
+		assert.Equal(t, expected.kind, kind, rev)
+	}
+
+	for _, rev := range []string{"bogus", "deadbeef"} {
+		_, _, err := ResolveRevision(context.Background(), "actions-go", "toolkit", rev)
+		assert.IsType(t, &ErrNotFound{}, err, rev)
+	}
+	_, _, err := ResolveRevision(context.Background(), "actions-go", "toolkit", "abcd")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ambiguous")
+	}
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// RepoMeta describes the repository running the workflow
+type RepoMeta struct {
+	Description   string
+	Topics        []string
+	Homepage      string
+	Language      string
+	Private       bool
+	Archived      bool
+	DefaultBranch string
+}
+
+var (
+	repoMetasLock sync.Mutex
+	// repoMetas caches the metadata of repositories by full name
+	repoMetas = map[string]*RepoMeta{}
+	// repoLicenses caches the licenses of repositories by full name
+	repoLicenses = map[string]*github.License{}
+)
+
+func repoMetaFrom(r *github.Repository) *RepoMeta {
+	topics := r.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return &RepoMeta{
+		Description:   r.GetDescription(),
+		Topics:        topics,
+		Homepage:      r.GetHomepage(),
+		Language:      r.GetLanguage(),
+		Private:       r.GetPrivate(),
+		Archived:      r.GetArchived(),
+		DefaultBranch: r.GetDefaultBranch(),
+	}
+}
+
+// RepositoryMetadata returns the metadata of the repository running the workflow.
+// It is read from the repository of the event payload when present, from the API otherwise, and cached for the lifetime of the process
+func RepositoryMetadata(ctx context.Context) (*RepoMeta, error) {
+	owner, repo := Context.Repo.Owner, Context.Repo.Repo
+	key := owner + "/" + repo
+	repoMetasLock.Lock()
+	defer repoMetasLock.Unlock()
+	if meta, ok := repoMetas[key]; ok {
+		return meta, nil
+	}
+	if r := Context.Payload.Repository; r != nil && r.GetOwner().GetLogin() == owner && r.GetName() == repo {
+		repoMetas[key] = repoMetaFrom(r)
+		return repoMetas[key], nil
+	}
+	r, _, err := GitHub.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s: %v", key, err)
+	}
+	repoMetas[key] = repoMetaFrom(r)
+	return repoMetas[key], nil
+}
+
+// Topics returns the topics of the repository running the workflow, see RepositoryMetadata
+func Topics(ctx context.Context) ([]string, error) {
+	meta, err := RepositoryMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return meta.Topics, nil
+}
+
+// NoAssertion is the SPDX identifier GitHub reports for licenses it does not detect
+const NoAssertion = "NOASSERTION"
+
+// License returns the SPDX identifier and the name of the license of the repository running the workflow.
+// It is read from the repository of the event payload when present, from the API otherwise, and cached for the lifetime of the process.
+// NoAssertion is returned, without error, for repositories without license or with a license GitHub does not detect
+func License(ctx context.Context) (spdxID, name string, err error) {
+	owner, repo := Context.Repo.Owner, Context.Repo.Repo
+	key := owner + "/" + repo
+	repoMetasLock.Lock()
+	defer repoMetasLock.Unlock()
+	license, ok := repoLicenses[key]
+	if !ok {
+		if r := Context.Payload.Repository; r != nil && r.GetOwner().GetLogin() == owner && r.GetName() == repo && r.License != nil {
+			license = r.License
+		} else {
+			l, resp, err := GitHub.Repositories.License(ctx, owner, repo)
+			switch {
+			case resp != nil && resp.StatusCode == http.StatusNotFound:
+				license = &github.License{}
+			case err != nil:
+				return "", "", fmt.Errorf("failed to get license of %s: %v", key, err)
+			default:
+				license = l.GetLicense()
+			}
+		}
+		repoLicenses[key] = license
+	}
+	spdxID = license.GetSPDXID()
+	if spdxID == "" {
+		spdxID = NoAssertion
+	}
+	return spdxID, license.GetName(), nil
+}
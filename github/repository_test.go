@@ -0,0 +1,113 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryMetadata(t *testing.T) {
+	defer func() { repoMetas = map[string]*RepoMeta{} }()
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"name": "toolkit", "owner": {"login": "actions-go"}, "description": "Go toolkit", "topics": ["go", "actions"],
+			"homepage": "https://actions-go.dev", "language": "Go", "private": false, "archived": true, "default_branch": "main"}`)
+	})
+	defer mockGitHub(mux)()
+	repo := ActionRepo{Owner: "actions-go", Repo: "toolkit"}
+
+	defer mockContext(ActionContext{Repo: repo, Payload: WebhookPayload{Repository: &github.Repository{
+		Name:          github.String("toolkit"),
+		Owner:         &github.User{Login: github.String("actions-go")},
+		Description:   github.String("From the payload"),
+		Topics:        []string{"payload"},
+		Private:       github.Bool(true),
+		DefaultBranch: github.String("master"),
+	}}})()
+	meta, err := RepositoryMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &RepoMeta{Description: "From the payload", Topics: []string{"payload"}, Private: true, DefaultBranch: "master"}, meta)
+	assert.Equal(t, 0, calls, "the payload repository avoids API calls")
+
+	t.Run("without repository in the payload, it is retrieved once from the API", func(t *testing.T) {
+		repoMetas = map[string]*RepoMeta{}
+		Context.Payload.Repository = nil
+		for i := 0; i < 2; i++ {
+			meta, err := RepositoryMetadata(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, &RepoMeta{
+				Description: "Go toolkit", Topics: []string{"go", "actions"}, Homepage: "https://actions-go.dev",
+				Language: "Go", Archived: true, DefaultBranch: "main",
+			}, meta)
+		}
+		topics, err := Topics(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "actions"}, topics)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestLicense(t *testing.T) {
+	defer func() { repoLicenses = map[string]*github.License{} }()
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/license", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"name": "LICENSE", "path": "LICENSE", "license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"}}`)
+	})
+	mux.HandleFunc("/repos/actions-go/unlicensed/license", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/custom/license", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "LICENSE", "license": {"key": "other", "name": "Other", "spdx_id": "NOASSERTION"}}`)
+	})
+	defer mockGitHub(mux)()
+
+	t.Run("the payload repository avoids API calls", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, Payload: WebhookPayload{Repository: &github.Repository{
+			Name:    github.String("toolkit"),
+			Owner:   &github.User{Login: github.String("actions-go")},
+			License: &github.License{Key: github.String("apache-2.0"), Name: github.String("Apache License 2.0"), SPDXID: github.String("Apache-2.0")},
+		}}})()
+		spdxID, name, err := License(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Apache-2.0", spdxID)
+		assert.Equal(t, "Apache License 2.0", name)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("without license in the payload, it is retrieved once from the API", func(t *testing.T) {
+		repoLicenses = map[string]*github.License{}
+		defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+		for i := 0; i < 2; i++ {
+			spdxID, name, err := License(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "MIT", spdxID)
+			assert.Equal(t, "MIT License", name)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("repositories without detected license have no assertion", func(t *testing.T) {
+		defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "unlicensed"}})()
+		spdxID, name, err := License(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, NoAssertion, spdxID)
+		assert.Equal(t, "", name)
+
+		Context.Repo.Repo = "custom"
+		spdxID, name, err = License(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, NoAssertion, spdxID)
+		assert.Equal(t, "Other", name)
+	})
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/actions-go/toolkit/core"
+)
+
+// toolOutput runs a tool and returns its standard output, it is replaced in tests
+var toolOutput = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// versionPattern matches the first version number printed by a tool, for example 1.21.3 in go version go1.21.3 linux/amd64
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+// runnerOSes maps GOOS values to the RUNNER_OS names, for runs outside of a runner
+var runnerOSes = map[string]string{"linux": "Linux", "windows": "Windows", "darwin": "macOS"}
+
+// RequireRunnerOS returns an error when the runner operating system, see core.RunnerOS, is not one of allowed.
+// Names are compared regardless of case. Outside of a runner, the operating system the action is built for is used
+func RequireRunnerOS(allowed ...string) error {
+	current := core.RunnerOS()
+	if current == "" {
+		current = runnerOSes[runtime.GOOS]
+	}
+	for _, name := range allowed {
+		if strings.EqualFold(name, current) {
+			return nil
+		}
+	}
+	return fmt.Errorf("this action requires a %s runner, it runs on %s", strings.Join(allowed, " or "), current)
+}
+
+// RequireToolVersion returns an error when tool is not installed, or when the first version number printed by
+// tool --version is lower than minVersion
+func RequireToolVersion(tool, minVersion string) error {
+	min, err := semver.NewVersion(minVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minimum version %s of %s: %v", minVersion, tool, err)
+	}
+	out, err := toolOutput(tool, "--version")
+	if err != nil {
+		return fmt.Errorf("this action requires %s %s or later: failed to run %s --version: %v", tool, minVersion, tool, err)
+	}
+	found := versionPattern.FindString(string(out))
+	if found == "" {
+		return fmt.Errorf("unable to find the version of %s in %q", tool, strings.TrimSpace(string(out)))
+	}
+	v, err := semver.NewVersion(found)
+	if err != nil {
+		return fmt.Errorf("invalid version %s of %s: %v", found, tool, err)
+	}
+	if v.LessThan(min) {
+		return fmt.Errorf("this action requires %s %s or later, found %s", tool, minVersion, found)
+	}
+	return nil
+}
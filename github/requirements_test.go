@@ -0,0 +1,46 @@
+package github
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRunnerOS(t *testing.T) {
+	defer setEnv(map[string]string{"RUNNER_OS": "Linux"})()
+	assert.NoError(t, RequireRunnerOS("Linux", "macOS"))
+	assert.NoError(t, RequireRunnerOS("linux"))
+	err := RequireRunnerOS("Windows", "macOS")
+	if assert.Error(t, err) {
+		assert.Equal(t, "this action requires a Windows or macOS runner, it runs on Linux", err.Error())
+	}
+}
+
+func TestRequireToolVersion(t *testing.T) {
+	previous := toolOutput
+	defer func() { toolOutput = previous }()
+	toolOutput = func(name string, args ...string) ([]byte, error) {
+		assert.Equal(t, []string{"--version"}, args)
+		switch name {
+		case "go":
+			return []byte("go version go1.21.3 linux/amd64\n"), nil
+		case "git":
+			return []byte("git version 2.39\n"), nil
+		case "silent":
+			return []byte("ok\n"), nil
+		}
+		return nil, errors.New("executable file not found in $PATH")
+	}
+
+	assert.NoError(t, RequireToolVersion("go", "1.21"))
+	assert.NoError(t, RequireToolVersion("go", "1.21.3"))
+	assert.NoError(t, RequireToolVersion("git", "2.30.0"))
+	err := RequireToolVersion("go", "1.22")
+	if assert.Error(t, err) {
+		assert.Equal(t, "this action requires go 1.22 or later, found 1.21.3", err.Error())
+	}
+	assert.Error(t, RequireToolVersion("docker", "20.10"))
+	assert.Error(t, RequireToolVersion("silent", "1.0"))
+	assert.Error(t, RequireToolVersion("go", "latest"))
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+const (
+	// searchResultsCap is the maximum number of results the search API returns for a query
+	searchResultsCap = 1000
+	searchPageSize   = 100
+)
+
+// throttle spaces calls by at least interval
+type throttle struct {
+	lock     sync.Mutex
+	interval time.Duration
+	last     time.Time
+}
+
+func (t *throttle) wait(ctx context.Context) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if d := t.last.Add(t.interval).Sub(now()); d > 0 {
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+	t.last = now()
+	return nil
+}
+
+// searchThrottle keeps search calls under the 30 requests per minute allowed to authenticated users
+var searchThrottle = &throttle{interval: 2 * time.Second}
+
+// searchPages calls search for each result page until all results, or the maximum GitHub returns, have been listed
+func searchPages(ctx context.Context, query string, search func(opts *github.SearchOptions) (total int, incomplete bool, resp *github.Response, err error)) error {
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: searchPageSize}}
+	for {
+		if err := searchThrottle.wait(ctx); err != nil {
+			return err
+		}
+		total, incomplete, resp, err := search(opts)
+		if err != nil {
+			return err
+		}
+		if resp.NextPage == 0 || resp.NextPage*searchPageSize > searchResultsCap {
+			if total > searchResultsCap || incomplete {
+				return &ErrSearchTruncated{Query: query, Total: total}
+			}
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// SearchIssuesEach calls fn for each issue or pull request matching the query, without buffering the results.
+// Iteration stops at the first error returned by fn.
+// An ErrSearchTruncated is returned when more issues than the search API returns are matching
+func SearchIssuesEach(ctx context.Context, query string, fn func(*github.Issue) error) error {
+	return searchPages(ctx, query, func(opts *github.SearchOptions) (int, bool, *github.Response, error) {
+		result, resp, err := GitHub.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return 0, false, resp, fmt.Errorf("failed to search %s: %v", query, err)
+		}
+		for _, issue := range result.Issues {
+			if err := fn(issue); err != nil {
+				return 0, false, resp, err
+			}
+		}
+		return result.GetTotal(), result.GetIncompleteResults(), resp, nil
+	})
+}
+
+// SearchIssues returns issues and pull requests matching the query.
+// When more issues than the search API returns are matching, those returned are provided along with an ErrSearchTruncated
+func SearchIssues(ctx context.Context, query string) ([]*github.Issue, error) {
+	issues := []*github.Issue{}
+	err := SearchIssuesEach(ctx, query, func(issue *github.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	if _, ok := err.(*ErrSearchTruncated); err != nil && !ok {
+		return nil, err
+	}
+	return issues, err
+}
+
+// SearchCode returns code matching the query.
+// When more files than the search API returns are matching, those returned are provided along with an ErrSearchTruncated
+func SearchCode(ctx context.Context, query string) ([]*github.CodeResult, error) {
+	results := []*github.CodeResult{}
+	err := searchPages(ctx, query, func(opts *github.SearchOptions) (int, bool, *github.Response, error) {
+		result, resp, err := GitHub.Search.Code(ctx, query, opts)
+		if err != nil {
+			return 0, false, resp, fmt.Errorf("failed to search %s: %v", query, err)
+		}
+		results = append(results, result.CodeResults...)
+		return result.GetTotal(), result.GetIncompleteResults(), resp, nil
+	})
+	if _, ok := err.(*ErrSearchTruncated); err != nil && !ok {
+		return nil, err
+	}
+	return results, err
+}
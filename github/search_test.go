@@ -0,0 +1,98 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func searchHandler(total int, calls *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		items := []string{}
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			items = append(items, fmt.Sprintf(`{"number": %d, "name": "file-%d"}`, i, i))
+		}
+		if page*perPage < total {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		fmt.Fprintf(w, `{"total_count": %d, "incomplete_results": false, "items": [%s]}`, total, strings.Join(items, ","))
+	}
+}
+
+func TestSearchIssues(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	searchThrottle.last = time.Time{}
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/issues", searchHandler(250, &calls))
+	defer mockGitHub(mux)()
+
+	issues, err := SearchIssues(context.Background(), "repo:actions-go/toolkit is:open")
+	assert.NoError(t, err)
+	assert.Len(t, issues, 250)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, waits, 2, "the search specific rate limiter must space requests")
+	for _, w := range waits {
+		assert.True(t, w > time.Second && w <= 2*time.Second)
+	}
+}
+
+func TestSearchIssuesCap(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/issues", searchHandler(2500, &calls))
+	defer mockGitHub(mux)()
+
+	issues, err := SearchIssues(context.Background(), "is:open")
+	assert.Len(t, issues, 1000)
+	assert.Equal(t, 10, calls)
+	if assert.IsType(t, &ErrSearchTruncated{}, err) {
+		assert.Equal(t, 2500, err.(*ErrSearchTruncated).Total)
+	}
+
+	t.Run("streaming stops at the first error", func(t *testing.T) {
+		calls = 0
+		seen := 0
+		stop := errors.New("stop")
+		err := SearchIssuesEach(context.Background(), "is:open", func(*github.Issue) error {
+			seen++
+			if seen == 150 {
+				return stop
+			}
+			return nil
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestSearchCode(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/code", searchHandler(120, &calls))
+	defer mockGitHub(mux)()
+
+	results, err := SearchCode(context.Background(), "Matcher repo:actions-go/toolkit")
+	assert.NoError(t, err)
+	assert.Len(t, results, 120)
+	assert.Equal(t, "file-119", results[119].GetName())
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strings"
+)
+
+// validateSignature checks signature, formatted as <prefix>=<hex digest>, is the HMAC of payload with secret
+func validateSignature(payload []byte, signature, secret, prefix string, h func() hash.Hash) error {
+	if secret == "" {
+		return &ErrInvalidSignature{Reason: "no secret configured"}
+	}
+	if !strings.HasPrefix(signature, prefix+"=") {
+		return &ErrInvalidSignature{Reason: "expected a " + prefix + "= prefix"}
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, prefix+"="))
+	if err != nil {
+		return &ErrInvalidSignature{Reason: "malformed digest"}
+	}
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return &ErrInvalidSignature{Reason: "signature mismatch"}
+	}
+	return nil
+}
+
+// ValidateSignature checks the X-Hub-Signature-256 header value of a webhook delivery, formatted as sha256=<hex digest>,
+// is the HMAC-SHA256 of the payload with the webhook secret. An ErrInvalidSignature is returned otherwise
+func ValidateSignature(payload []byte, signature, secret string) error {
+	return validateSignature(payload, signature, secret, "sha256", sha256.New)
+}
+
+// ValidateSignatureSHA1 checks the legacy X-Hub-Signature header value, formatted as sha1=<hex digest>.
+// Prefer ValidateSignature, GitHub sends both headers
+func ValidateSignatureSHA1(payload []byte, signature, secret string) error {
+	return validateSignature(payload, signature, secret, "sha1", sha1.New)
+}
@@ -0,0 +1,31 @@
+package github_test
+
+import (
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSignature(t *testing.T) {
+	payload, secret := []byte("Hello, World!"), "It's a Secret to Everybody"
+
+	assert.NoError(t, github.ValidateSignature(payload, "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", secret))
+	assert.NoError(t, github.ValidateSignatureSHA1(payload, "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59", secret))
+
+	for name, c := range map[string]struct {
+		signature, secret, reason string
+	}{
+		"wrong secret":     {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", "other secret", "signature mismatch"},
+		"altered digest":   {"sha256=857107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", secret, "signature mismatch"},
+		"missing prefix":   {"757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", secret, "expected a sha256= prefix"},
+		"sha1 signature":   {"sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59", secret, "expected a sha256= prefix"},
+		"malformed digest": {"sha256=not-hexadecimal", secret, "malformed digest"},
+		"empty secret":     {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", "", "no secret configured"},
+	} {
+		err := github.ValidateSignature(payload, c.signature, c.secret)
+		if assert.IsType(t, &github.ErrInvalidSignature{}, err, name) {
+			assert.Equal(t, c.reason, err.(*github.ErrInvalidSignature).Reason, name)
+		}
+	}
+}
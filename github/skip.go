@@ -0,0 +1,29 @@
+package github
+
+import (
+	"context"
+	"strings"
+)
+
+// SkipDirectives are the commit message directives ShouldSkip looks for by default, the ones GitHub honours to skip
+// workflows
+var SkipDirectives = []string{"[skip ci]", "[ci skip]", "[no ci]", "[skip actions]", "[actions skip]", "***NO_CI***"}
+
+// ShouldSkip returns whether the message of the commit being processed, see CommitMessage, contains one of directives,
+// SkipDirectives when none is provided. The directive found is returned along, directives are matched regardless of case
+func ShouldSkip(ctx context.Context, directives ...string) (bool, string, error) {
+	if len(directives) == 0 {
+		directives = SkipDirectives
+	}
+	message, err := CommitMessage(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	message = strings.ToLower(message)
+	for _, directive := range directives {
+		if directive != "" && strings.Contains(message, strings.ToLower(directive)) {
+			return true, directive, nil
+		}
+	}
+	return false, "", nil
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldSkip(t *testing.T) {
+	message := ""
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/commits/d74fd518cf0410699c6b748924727686c1606d00", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"sha": "d74fd518cf0410699c6b748924727686c1606d00", "commit": map[string]string{"message": message}})
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}, SHA: "d74fd518cf0410699c6b748924727686c1606d00"})()
+
+	for directive, m := range map[string]string{
+		"[skip ci]":      "Update README [skip ci]",
+		"[ci skip]":      "Update README\n\n[CI SKIP]",
+		"[no ci]":        "[no ci] Update README",
+		"[skip actions]": "Update README [skip actions]",
+		"***NO_CI***":    "Update README ***NO_CI***",
+	} {
+		message = m
+		skip, found, err := ShouldSkip(context.Background())
+		require.NoError(t, err)
+		assert.True(t, skip, m)
+		assert.Equal(t, directive, found)
+	}
+
+	message = "Update README, skip ci later"
+	skip, found, err := ShouldSkip(context.Background())
+	require.NoError(t, err)
+	assert.False(t, skip)
+	assert.Equal(t, "", found)
+
+	message = "Update README [skip release]"
+	skip, _, err = ShouldSkip(context.Background())
+	require.NoError(t, err)
+	assert.False(t, skip)
+	skip, found, err = ShouldSkip(context.Background(), "[skip release]")
+	require.NoError(t, err)
+	assert.True(t, skip)
+	assert.Equal(t, "[skip release]", found)
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// staleIssuesQuery returns the search query listing open issues and pull requests of the repository not updated since cutoff
+func staleIssuesQuery(cutoff time.Time, labels []string) string {
+	query := fmt.Sprintf("repo:%s/%s is:open updated:<%s", Context.Repo.Owner, Context.Repo.Repo, cutoff.UTC().Format(time.RFC3339))
+	for _, label := range labels {
+		query += fmt.Sprintf(` label:"%s"`, label)
+	}
+	return query
+}
+
+// StaleIssues returns the open issues and pull requests of the repository running the workflow not updated within olderThan.
+// When labels are provided, only issues having all of them are returned.
+// As results are listed using the search API, at most 1000 issues are returned along with an ErrSearchTruncated
+func StaleIssues(ctx context.Context, olderThan time.Duration, labels []string) ([]*github.Issue, error) {
+	cutoff := now().Add(-olderThan)
+	issues := []*github.Issue{}
+	err := SearchIssuesEach(ctx, staleIssuesQuery(cutoff, labels), func(issue *github.Issue) error {
+		// the search index may lag behind, double check the issue is still stale
+		if issue.GetUpdatedAt().Before(cutoff) {
+			issues = append(issues, issue)
+		}
+		return nil
+	})
+	if _, ok := err.(*ErrSearchTruncated); err != nil && !ok {
+		return nil, err
+	}
+	return issues, err
+}
+
+// MarkStale adds label to an issue or pull request of the repository running the workflow and, when not empty, posts comment on it.
+// The label is created when missing
+func MarkStale(ctx context.Context, issue *github.Issue, label, comment string) error {
+	if err := AddLabels(ctx, issue.GetNumber(), label); err != nil {
+		return err
+	}
+	if comment == "" {
+		return nil
+	}
+	err := RetryRateLimited(ctx, func() (*github.Response, error) {
+		_, resp, err := GitHub.Issues.CreateComment(ctx, Context.Repo.Owner, Context.Repo.Repo, issue.GetNumber(), &github.IssueComment{Body: github.String(comment)})
+		return resp, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to comment issue %d: %v", issue.GetNumber(), err)
+	}
+	return nil
+}
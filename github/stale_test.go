@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleIssues(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	fixed := time.Date(2020, 6, 30, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	searchThrottle.last = time.Time{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `repo:actions-go/toolkit is:open updated:<2020-06-01T12:00:00Z label:"bug" label:"help wanted"`, r.URL.Query().Get("q"))
+		fmt.Fprint(w, `{"total_count": 3, "incomplete_results": false, "items": [
+			{"number": 1, "updated_at": "2020-01-10T00:00:00Z"},
+			{"number": 2, "updated_at": "2020-05-31T23:00:00Z"},
+			{"number": 3, "updated_at": "2020-06-15T00:00:00Z"}
+		]}`)
+	})
+	labels := []string{}
+	comments := []string{}
+	mux.HandleFunc("/repos/actions-go/toolkit/labels/stale", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "stale", "color": "ededed"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/1/labels", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&labels))
+		fmt.Fprint(w, `[{"name": "stale"}]`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		c := map[string]string{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&c))
+		comments = append(comments, c["body"])
+		fmt.Fprint(w, `{"id": 1}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	issues, err := StaleIssues(context.Background(), 29*24*time.Hour, []string{"bug", "help wanted"})
+	require.NoError(t, err)
+	if assert.Len(t, issues, 2, "issues updated after the cutoff must be filtered out") {
+		assert.Equal(t, 1, issues[0].GetNumber())
+		assert.Equal(t, 2, issues[1].GetNumber())
+	}
+
+	require.NoError(t, MarkStale(context.Background(), issues[0], "stale", "This issue has been inactive for 29 days"))
+	assert.Equal(t, []string{"stale"}, labels)
+	assert.Equal(t, []string{"This issue has been inactive for 29 days"}, comments)
+
+	require.NoError(t, MarkStale(context.Background(), issues[0], "stale", ""))
+	assert.Len(t, comments, 1, "no comment must be posted when empty")
+}
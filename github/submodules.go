@@ -0,0 +1,174 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+const gitmodulesFile = ".gitmodules"
+
+// Submodule is a submodule declared in a .gitmodules file
+type Submodule struct {
+	Name string
+	Path string
+	URL  string
+}
+
+// ParseGitmodules returns the submodules declared in the content of a .gitmodules file, in declaration order
+func ParseGitmodules(data []byte) ([]Submodule, error) {
+	submodules := []Submodule{}
+	var current *Submodule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			current = nil
+			section := strings.TrimSpace(strings.Trim(line, "[]"))
+			if strings.HasPrefix(section, "submodule ") {
+				submodules = append(submodules, Submodule{Name: strings.Trim(strings.TrimPrefix(section, "submodule "), ` "`)})
+				current = &submodules[len(submodules)-1]
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid line %q in submodule %s", line, current.Name)
+		}
+		value := strings.Trim(strings.TrimSpace(parts[1]), `"`)
+		switch strings.ToLower(strings.TrimSpace(parts[0])) {
+		case "path":
+			current.Path = strings.Trim(value, "/")
+		case "url":
+			current.URL = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, s := range submodules {
+		if s.Path == "" || s.URL == "" {
+			return nil, fmt.Errorf("submodule %s must have both a path and an url", s.Name)
+		}
+	}
+	return submodules, nil
+}
+
+// submoduleRepository returns the GitHub repository of a submodule url, relative urls are resolved against owner/repo
+func submoduleRepository(owner, repo, u string) (string, string, error) {
+	var p string
+	switch {
+	case strings.HasPrefix(u, "./") || strings.HasPrefix(u, "../"):
+		p = path.Join("/", owner, repo, u)
+	case strings.HasPrefix(u, "git@github.com:"):
+		p = strings.TrimPrefix(u, "git@github.com:")
+	default:
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid submodule url %s: %v", u, err)
+		}
+		if parsed.Hostname() != "github.com" {
+			return "", "", fmt.Errorf("submodule url %s is not hosted on github.com", u)
+		}
+		p = parsed.Path
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(p, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("submodule url %s does not point to a repository", u)
+	}
+	return parts[0], parts[1], nil
+}
+
+// submoduleSHA returns the commit a gitlink entry of a repository points to at ref
+func submoduleSHA(c *http.Client, owner, repo, ref, p string) (string, error) {
+	u := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s?ref=%s", owner, repo, p, url.QueryEscape(ref))
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return "", err
+	}
+	authorize(req)
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", &ErrNotFound{Resource: fmt.Sprintf("submodule %s of %s/%s", p, owner, repo), Err: fmt.Errorf("unexpected code %d", resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected code %d", resp.StatusCode)
+	}
+	entry := struct {
+		Type string `json:"type"`
+		SHA  string `json:"sha"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		return "", err
+	}
+	if entry.Type != "submodule" {
+		return "", fmt.Errorf("%s is not a submodule in %s/%s", p, owner, repo)
+	}
+	return entry.SHA, nil
+}
+
+func includeGitmodules(include Matcher) Matcher {
+	return func(p string) bool {
+		return p == gitmodulesFile || include(p)
+	}
+}
+
+// downloadSubmodules adds the files of the submodules declared in the .gitmodules of files, prefixed with their path
+func downloadSubmodules(c *http.Client, owner, repo, ref string, files map[string]RepositoryFile, include Matcher, options *DownloadOptions) (map[string]RepositoryFile, error) {
+	gitmodules, ok := files[gitmodulesFile]
+	if !include(gitmodulesFile) {
+		delete(files, gitmodulesFile)
+	}
+	if !ok {
+		return files, nil
+	}
+	submodules, err := ParseGitmodules(gitmodules.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s of %s/%s: %v", gitmodulesFile, owner, repo, err)
+	}
+	for _, s := range submodules {
+		subOwner, subRepo, err := submoduleRepository(owner, repo, s.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download submodule %s: %v", s.Path, err)
+		}
+		sha, err := submoduleSHA(c, owner, repo, ref, s.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download submodule %s: %v", s.Path, err)
+		}
+		core.Debugf("Downloading submodule %s from %s/%s@%s", s.Path, subOwner, subRepo, sha)
+		prefix := s.Path + "/"
+		nested := *options
+		nested.submodules--
+		if options.Transform != nil {
+			nested.Transform = func(p string, data []byte) ([]byte, error) {
+				return options.Transform(prefix+p, data)
+			}
+		}
+		subFiles, _, err := downloadTarball(c, subOwner, subRepo, sha, func(p string) bool { return include(prefix + p) }, &nested, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to download submodule %s: %v", s.Path, err)
+		}
+		for p, f := range subFiles {
+			f.Path = prefix + f.Path
+			files[prefix+p] = f
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,98 @@
+package github_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/actions-go/toolkit/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tarball(t *testing.T, files map[string]github.RepositoryFile) []byte {
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, github.WriteTarGz(b, files))
+	return b.Bytes()
+}
+
+func TestDownloadSubmodules(t *testing.T) {
+	gitmodules := []byte("[submodule \"lib\"]\n\tpath = vendor/lib\n\turl = ../lib.git\n")
+	toolkit := tarball(t, map[string]github.RepositoryFile{
+		"actions-go-toolkit-d74fd51/.gitmodules": {Data: gitmodules},
+		"actions-go-toolkit-d74fd51/main.go":     {Data: []byte("package main")},
+	})
+	lib := tarball(t, map[string]github.RepositoryFile{
+		"actions-go-lib-5e2a9c1/lib.go":    {Data: []byte("package lib")},
+		"actions-go-lib-5e2a9c1/README.md": {Data: []byte("# lib")},
+	})
+	c := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: r, Body: ioutil.NopCloser(bytes.NewReader(nil))}
+		switch r.URL.Path {
+		case "/repos/actions-go/toolkit/tarball/main":
+			resp.Header.Set("Content-Type", "application/gzip")
+			resp.Body = ioutil.NopCloser(bytes.NewReader(toolkit))
+		case "/repos/actions-go/toolkit/contents/vendor/lib":
+			assert.Equal(t, "main", r.URL.Query().Get("ref"))
+			resp.Body = ioutil.NopCloser(bytes.NewBufferString(`{"type": "submodule", "sha": "5e2a9c1"}`))
+		case "/repos/actions-go/lib/tarball/5e2a9c1":
+			resp.Header.Set("Content-Type", "application/gzip")
+			resp.Body = ioutil.NopCloser(bytes.NewReader(lib))
+		default:
+			resp.StatusCode = http.StatusNotFound
+		}
+		return resp, nil
+	})}
+
+	files, err := github.DownloadRepositoryFiles(c, "actions-go", "toolkit", "main", github.MatchesOneOf("\\.go$"), nil)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "submodules are not downloaded by default")
+
+	files, err = github.DownloadRepositoryFiles(c, "actions-go", "toolkit", "main", github.MatchesOneOf("\\.go$"), (&github.DownloadOptions{}).WithSubmodules(1))
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "package main", string(files["main.go"].Data))
+	assert.Equal(t, "package lib", string(files["vendor/lib/lib.go"].Data))
+	assert.Equal(t, "vendor/lib/lib.go", files["vendor/lib/lib.go"].Path)
+	assert.NotContains(t, files, ".gitmodules", ".gitmodules is only kept when matched")
+
+	files, err = github.DownloadRepositoryFiles(c, "actions-go", "toolkit", "main", github.MatchesOneOf(".*"), (&github.DownloadOptions{}).WithSubmodules(1))
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+	assert.Contains(t, files, ".gitmodules")
+	assert.Contains(t, files, "vendor/lib/README.md")
+
+	t.Run("size limits apply to the whole download", func(t *testing.T) {
+		// the superproject and the submodule each fit in the limit, not both
+		limit := int64(len(gitmodules) + len("package main") + len("# lib"))
+		_, err := github.DownloadRepositoryFiles(c, "actions-go", "toolkit", "main", github.MatchesOneOf(".*"), &github.DownloadOptions{MaxTotalBytes: limit})
+		require.NoError(t, err)
+		_, err = github.DownloadRepositoryFiles(c, "actions-go", "toolkit", "main", github.MatchesOneOf(".*"), (&github.DownloadOptions{MaxTotalBytes: limit}).WithSubmodules(1))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "exceeds the total limit")
+		}
+	})
+}
+
+func TestParseGitmodules(t *testing.T) {
+	submodules, err := github.ParseGitmodules([]byte(`
+# comments are skipped
+[submodule "lib"]
+	path = vendor/lib
+	url = https://github.com/actions-go/lib.git
+[core]
+	path = ignored
+[submodule "tools"]
+	url = git@github.com:actions-go/tools.git
+	path = "tools"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []github.Submodule{
+		{Name: "lib", Path: "vendor/lib", URL: "https://github.com/actions-go/lib.git"},
+		{Name: "tools", Path: "tools", URL: "git@github.com:actions-go/tools.git"},
+	}, submodules)
+
+	_, err = github.ParseGitmodules([]byte("[submodule \"lib\"]\n\tpath = vendor/lib\n"))
+	assert.Error(t, err)
+}
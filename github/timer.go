@@ -0,0 +1,43 @@
+package github
+
+import (
+	"sync"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+type timing struct {
+	name    string
+	elapsed time.Duration
+}
+
+var (
+	timingsLock sync.Mutex
+	timings     []timing
+)
+
+// Timer starts timing a phase of the action, the returned function, typically deferred, logs the elapsed duration
+// and records it for TimingsSummary
+func Timer(name string) func() {
+	start := now()
+	return func() {
+		elapsed := now().Sub(start)
+		core.Infof("%s took %s", name, elapsed)
+		timingsLock.Lock()
+		defer timingsLock.Unlock()
+		timings = append(timings, timing{name: name, elapsed: elapsed})
+	}
+}
+
+// TimingsSummary returns a row with the name and elapsed duration of each stopped timer, in the order they were stopped.
+// Rows can be added to a core.TableBuilder with Phase and Duration headers
+func TimingsSummary() [][]string {
+	timingsLock.Lock()
+	defer timingsLock.Unlock()
+	rows := make([][]string, len(timings))
+	for i, t := range timings {
+		rows[i] = []string{t.name, t.elapsed.String()}
+	}
+	return rows
+}
@@ -0,0 +1,25 @@
+package github
+
+import (
+	"testing"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimer(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	defer func() { timings = nil }()
+
+	stopBuild := Timer("build")
+	<-after(90 * time.Second)
+	stopTest := Timer("test")
+	<-after(2 * time.Second)
+	stopTest()
+	stopBuild()
+
+	assert.Equal(t, [][]string{{"test", "2s"}, {"build", "1m32s"}}, TimingsSummary())
+	assert.Contains(t, core.NewTableBuilder().Header("Phase", "Duration").Row(TimingsSummary()[0]...).Render(), "| test | 2s |")
+}
@@ -0,0 +1,49 @@
+package github_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/actions-go/toolkit/core"
+	"github.com/actions-go/toolkit/github"
+	gogithub "github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSOptions(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "toolkit"}`)
+	}))
+	defer s.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+
+	get := func(c *gogithub.Client) error {
+		c.BaseURL, _ = url.Parse(s.URL + "/")
+		_, _, err := c.Repositories.Get(context.Background(), "actions-go", "toolkit")
+		return err
+	}
+
+	assert.Error(t, get(github.NewClient()), "the test server certificate must not be trusted by default")
+	assert.NoError(t, get(github.NewClient(github.WithCACert(ca))))
+
+	resp, err := github.NewHTTPClient(github.WithCACert(ca)).Get(s.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	t.Run("skipping verification logs a warning", func(t *testing.T) {
+		b := bytes.NewBuffer(nil)
+		core.SetStdout(b)
+		defer core.SetStdout(os.Stdout)
+		assert.NoError(t, get(github.NewClient(github.WithInsecureSkipVerify(true))))
+		assert.Contains(t, b.String(), "::warning::TLS certificate verification is disabled")
+	})
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// TokenSource is a place a GitHub token can be read from, see TokenFrom
+type TokenSource struct {
+	name  string
+	token func() (string, error)
+}
+
+// String returns a description of the source, used in errors
+func (s TokenSource) String() string {
+	return s.name
+}
+
+// EnvToken reads the token from the GITHUB_TOKEN environment variable
+var EnvToken = TokenSource{
+	name: "GITHUB_TOKEN environment variable",
+	token: func() (string, error) {
+		return getenv("GITHUB_TOKEN"), nil
+	},
+}
+
+// InputToken reads the token from the action input name
+func InputToken(name string) TokenSource {
+	return TokenSource{
+		name: fmt.Sprintf("input %s", name),
+		token: func() (string, error) {
+			t, _ := core.GetInput(name)
+			return t, nil
+		},
+	}
+}
+
+// FileToken reads the token from the file at path, surrounding spaces are trimmed
+func FileToken(path string) TokenSource {
+	return TokenSource{
+		name: fmt.Sprintf("file %s", path),
+		token: func() (string, error) {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimSpace(string(b)), nil
+		},
+	}
+}
+
+// TokenFrom returns the first non-empty token of sources, tried in order.
+// When none provides a token, the error lists every source tried and why it failed
+func TokenFrom(sources ...TokenSource) (string, error) {
+	tried := []string{}
+	for _, s := range sources {
+		t, err := s.token()
+		if err != nil {
+			tried = append(tried, fmt.Sprintf("%s: %v", s, err))
+			continue
+		}
+		if t != "" {
+			return t, nil
+		}
+		tried = append(tried, fmt.Sprintf("%s: empty", s))
+	}
+	if len(tried) == 0 {
+		return "", fmt.Errorf("no token source provided")
+	}
+	return "", fmt.Errorf("no token found, tried %s", strings.Join(tried, ", "))
+}
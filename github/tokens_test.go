@@ -0,0 +1,93 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokens")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(path, []byte("file-token\n"), 0600))
+	defer setEnv(map[string]string{"GITHUB_TOKEN": "env-token", "INPUT_PAT": "input-token", "INPUT_TOKEN": ""})()
+
+	found, err := TokenFrom(InputToken("pat"), EnvToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "input-token", found)
+	found, err = TokenFrom(EnvToken, InputToken("pat"))
+	assert.NoError(t, err)
+	assert.Equal(t, "env-token", found)
+	found, err = TokenFrom(FileToken(filepath.Join(dir, "missing")), InputToken("token"), FileToken(path), EnvToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "file-token", found)
+	assert.Equal(t, "env-token", token())
+
+	os.Setenv("GITHUB_TOKEN", "")
+	_, err = TokenFrom(EnvToken, InputToken("token"), FileToken(filepath.Join(dir, "missing")))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "GITHUB_TOKEN environment variable: empty, input token: empty, file ")
+	}
+	_, err = TokenFrom()
+	assert.Error(t, err)
+}
+
+func TestAppInstallationToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		require.Len(t, parts, 3)
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(t, err)
+		assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig))
+		b, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+		claims := map[string]int64{}
+		require.NoError(t, json.Unmarshal(b, &claims))
+		assert.EqualValues(t, 1234, claims["iss"])
+		assert.True(t, claims["exp"] > claims["iat"])
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"token": "ghs_installation", "expires_at": "2030-01-01T00:00:00Z"}`)
+	})
+	mux.HandleFunc("/app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Integration not found"}`)
+	})
+	defer mockGitHub(mux)()
+
+	token, err := TokenFrom(AppInstallationToken(1234, 42, privateKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_installation", token)
+
+	_, err = TokenFrom(AppInstallationToken(1234, 7, privateKey))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "installation 7 of app 1234: failed to create installation token")
+		assert.Contains(t, err.Error(), "unexpected code 404: Integration not found", "the API message is reported")
+	}
+	_, err = TokenFrom(AppInstallationToken(1234, 42, []byte("not a key")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ListFiles returns the sorted path of all files of a repository at ref, without downloading their content.
+// Submodules are not listed. When the repository is too large for the trees API, an ErrTreeTruncated is returned
+func ListFiles(ctx context.Context, owner, repo, ref string) ([]string, error) {
+	return ListFilesMatching(ctx, owner, repo, ref, func(string) bool { return true })
+}
+
+// ListFilesMatching returns the path of files of a repository, see ListFiles, that the include matcher accepts
+func ListFilesMatching(ctx context.Context, owner, repo, ref string, include Matcher) ([]string, error) {
+	tree, _, err := GitHub.Git.GetTree(ctx, owner, repo, ref, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %s/%s at %s: %v", owner, repo, ref, err)
+	}
+	if tree.GetTruncated() {
+		return nil, &ErrTreeTruncated{Owner: owner, Repo: repo, Ref: ref}
+	}
+	paths := []string{}
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" && include(entry.GetPath()) {
+			paths = append(paths, entry.GetPath())
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// subtreeSHA returns the SHA of the tree of dir at ref, ok is false when the directory does not exist
+func subtreeSHA(ctx context.Context, owner, repo, ref, dir string) (sha string, ok bool, err error) {
+	sha = ref
+	for _, name := range strings.Split(dir, "/") {
+		tree, _, err := GitHub.Git.GetTree(ctx, owner, repo, sha, false)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get tree of %s/%s at %s: %v", owner, repo, ref, err)
+		}
+		found := false
+		for _, entry := range tree.Entries {
+			if entry.GetPath() == name && entry.GetType() == "tree" {
+				sha, found = entry.GetSHA(), true
+				break
+			}
+		}
+		if !found {
+			return "", false, nil
+		}
+	}
+	return sha, true, nil
+}
+
+// ListFilesMatchingGlobs returns the sorted path of files of a repository matching one of the globs, see MatchesGlobs.
+// When the globs are restricted to some directories, see GlobPrefixes, only the trees of these directories are listed,
+// which avoids listing the whole tree of large repositories. Otherwise it behaves like ListFilesMatching
+func ListFilesMatchingGlobs(ctx context.Context, owner, repo, ref string, globs ...string) ([]string, error) {
+	include := MatchesGlobs(globs...)
+	prefixes, ok := GlobPrefixes(globs...)
+	if !ok {
+		return ListFilesMatching(ctx, owner, repo, ref, include)
+	}
+	paths := []string{}
+	for _, prefix := range prefixes {
+		sha, ok, err := subtreeSHA(ctx, owner, repo, ref, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		tree, _, err := GitHub.Git.GetTree(ctx, owner, repo, sha, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree of %s in %s/%s at %s: %v", prefix, owner, repo, ref, err)
+		}
+		if tree.GetTruncated() {
+			return nil, &ErrTreeTruncated{Owner: owner, Repo: repo, Ref: ref}
+		}
+		for _, entry := range tree.Entries {
+			p := prefix + "/" + entry.GetPath()
+			if entry.GetType() == "blob" && include(p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
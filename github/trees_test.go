@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("recursive"))
+		fmt.Fprint(w, `{"sha": "d74fd51", "truncated": false, "tree": [
+			{"path": "go.mod", "type": "blob"},
+			{"path": "core", "type": "tree"},
+			{"path": "core/core.go", "type": "blob"},
+			{"path": "README.md", "type": "blob"},
+			{"path": "vendor/lib", "type": "commit"}
+		]}`)
+	})
+	mux.HandleFunc("/repos/actions-go/large/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "d74fd51", "truncated": true, "tree": [{"path": "go.mod", "type": "blob"}]}`)
+	})
+	defer mockGitHub(mux)()
+
+	files, err := ListFiles(context.Background(), "actions-go", "toolkit", "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "core/core.go", "go.mod"}, files)
+
+	files, err = ListFilesMatching(context.Background(), "actions-go", "toolkit", "main", MatchesOneOf("\\.go$"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"core/core.go"}, files)
+
+	t.Run("truncated trees return a typed error", func(t *testing.T) {
+		files, err := ListFiles(context.Background(), "actions-go", "large", "main")
+		assert.Nil(t, files)
+		if assert.IsType(t, &ErrTreeTruncated{}, err) {
+			assert.Equal(t, "tree of actions-go/large at main is truncated", err.Error())
+		}
+	})
+}
+
+func TestListFilesMatchingGlobs(t *testing.T) {
+	requests := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/git/trees/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?recursive="+r.URL.Query().Get("recursive"))
+		switch r.URL.Path {
+		case "/repos/actions-go/toolkit/git/trees/main":
+			if r.URL.Query().Get("recursive") != "" {
+				fmt.Fprint(w, `{"sha": "root", "tree": [{"path": "go.mod", "type": "blob"}, {"path": "docs/index.md", "type": "blob"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"sha": "root", "tree": [{"path": "go.mod", "type": "blob"}, {"path": "docs", "type": "tree", "sha": "docs-sha"}, {"path": "src", "type": "tree", "sha": "src-sha"}]}`)
+		case "/repos/actions-go/toolkit/git/trees/src-sha":
+			fmt.Fprint(w, `{"sha": "src-sha", "tree": [{"path": "cmd", "type": "tree", "sha": "cmd-sha"}, {"path": "main.go", "type": "blob"}]}`)
+		case "/repos/actions-go/toolkit/git/trees/cmd-sha":
+			assert.Equal(t, "1", r.URL.Query().Get("recursive"))
+			fmt.Fprint(w, `{"sha": "cmd-sha", "tree": [{"path": "run", "type": "tree"}, {"path": "run/main.go", "type": "blob"}, {"path": "run/README.md", "type": "blob"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	defer mockGitHub(mux)()
+
+	files, err := ListFilesMatchingGlobs(context.Background(), "actions-go", "toolkit", "main", "src/cmd/**/*.go", "lib/**")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/cmd/run/main.go"}, files)
+	assert.Equal(t, []string{
+		"/repos/actions-go/toolkit/git/trees/main?recursive=",
+		"/repos/actions-go/toolkit/git/trees/main?recursive=",
+		"/repos/actions-go/toolkit/git/trees/src-sha?recursive=",
+		"/repos/actions-go/toolkit/git/trees/cmd-sha?recursive=1",
+	}, requests, "only the trees of the globs directories are listed")
+
+	t.Run("globs matching anywhere list the whole tree", func(t *testing.T) {
+		requests = []string{}
+		files, err := ListFilesMatchingGlobs(context.Background(), "actions-go", "toolkit", "main", "**/*.md")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs/index.md"}, files)
+		assert.Equal(t, []string{"/repos/actions-go/toolkit/git/trees/main?recursive=1"}, requests)
+	})
+}
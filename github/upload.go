@@ -0,0 +1,312 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+const (
+	// artifactBlockSize is the default size of the blocks the artifact archive is uploaded in
+	artifactBlockSize   = 8 * 1024 * 1024
+	artifactServicePath = "twirp/github.actions.results.api.v1.ArtifactService/"
+	artifactVersion     = 4
+	uploadRetries       = 3
+	uploadRetryInterval = time.Second
+)
+
+// UploadOptions defines available options to upload an artifact
+type UploadOptions struct {
+	// Concurrency is the number of blocks uploaded in parallel, 2 by default
+	Concurrency int
+	// ChunkSize is the size of the blocks the artifact archive is uploaded in, 8MiB by default
+	ChunkSize int64
+	// Progress, when set, is called each time a block has been uploaded with the number of bytes uploaded so far
+	// and the size of the artifact archive. It may be called concurrently
+	Progress func(uploaded, total int64)
+}
+
+func (o *UploadOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return 2
+	}
+	return o.Concurrency
+}
+
+func (o *UploadOptions) chunkSize() int64 {
+	if o.ChunkSize <= 0 {
+		return artifactBlockSize
+	}
+	return o.ChunkSize
+}
+
+// artifactClient talks to the artifact service of the runner, it is only reachable from within a workflow run
+type artifactClient struct {
+	resultsURL   string
+	token        string
+	runBackendID string
+	jobBackendID string
+	client       *http.Client
+}
+
+func newArtifactClient() (*artifactClient, error) {
+	resultsURL, token := getenv("ACTIONS_RESULTS_URL"), getenv("ACTIONS_RUNTIME_TOKEN")
+	if resultsURL == "" || token == "" {
+		return nil, fmt.Errorf("unable to reach the artifact service, ACTIONS_RESULTS_URL and ACTIONS_RUNTIME_TOKEN must be set")
+	}
+	runBackendID, jobBackendID, err := artifactBackendIDs(token)
+	if err != nil {
+		return nil, err
+	}
+	return &artifactClient{
+		resultsURL:   strings.TrimSuffix(resultsURL, "/") + "/",
+		token:        token,
+		runBackendID: runBackendID,
+		jobBackendID: jobBackendID,
+		client:       baseHTTPClient(),
+	}, nil
+}
+
+// artifactBackendIDs reads the workflow run and job identifiers the artifact service knows the current job by
+// from the Actions.Results scope of the runtime token
+func artifactBackendIDs(token string) (string, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", "", fmt.Errorf("failed to read the runtime token: not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the runtime token: %v", err)
+	}
+	claims := struct {
+		Scope string `json:"scp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", "", fmt.Errorf("failed to read the runtime token: %v", err)
+	}
+	for _, scope := range strings.Fields(claims.Scope) {
+		ids := strings.Split(scope, ":")
+		if len(ids) == 3 && ids[0] == "Actions.Results" {
+			return ids[1], ids[2], nil
+		}
+	}
+	return "", "", fmt.Errorf("failed to read the runtime token: no Actions.Results scope")
+}
+
+// call invokes a method of the artifact service, errors hold the message returned by the service
+func (c *artifactClient) call(ctx context.Context, method string, payload, v interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.resultsURL+artifactServicePath+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		twirpErr := struct {
+			Code string `json:"code"`
+			Msg  string `json:"msg"`
+		}{}
+		if json.NewDecoder(resp.Body).Decode(&twirpErr) == nil && twirpErr.Msg != "" {
+			return fmt.Errorf("unexpected code %d: %s", resp.StatusCode, twirpErr.Msg)
+		}
+		return fmt.Errorf("unexpected code %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// putBlob sends a request to the blob storage the artifact is uploaded to, it is authorized by the signed URL itself
+func (c *artifactClient) putBlob(ctx context.Context, signedURL string, query url.Values, header http.Header, body []byte) error {
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	for k, values := range query {
+		q[k] = values
+	}
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected code %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// uploadBlock uploads a block of the artifact archive, retrying with an exponential backoff when it fails
+func (c *artifactClient) uploadBlock(ctx context.Context, signedURL, id string, data []byte) error {
+	var err error
+	interval := uploadRetryInterval
+	for attempt := 1; ; attempt++ {
+		err = c.putBlob(ctx, signedURL, url.Values{"comp": []string{"block"}, "blockid": []string{id}}, nil, data)
+		if err == nil || attempt == uploadRetries {
+			return err
+		}
+		core.Debugf("failed to upload block %s, retrying in %v: %v", id, interval, err)
+		if err := sleep(ctx, interval); err != nil {
+			return err
+		}
+		interval *= 2
+	}
+}
+
+// commitBlocks assembles the uploaded blocks, in order, into the artifact archive
+func (c *artifactClient) commitBlocks(ctx context.Context, signedURL string, ids []string) error {
+	body := bytes.NewBufferString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for _, id := range ids {
+		fmt.Fprintf(body, "<Latest>%s</Latest>", id)
+	}
+	body.WriteString("</BlockList>")
+	header := http.Header{"Content-Type": []string{"application/xml"}, "X-Ms-Blob-Content-Type": []string{"application/zip"}}
+	return c.putBlob(ctx, signedURL, url.Values{"comp": []string{"blocklist"}}, header, body.Bytes())
+}
+
+// UploadArtifact uploads files as an artifact of the current workflow run. Files are archived in a zip whose blocks
+// are uploaded concurrently, and the artifact is only finalized once all of them have been uploaded
+func UploadArtifact(ctx context.Context, name string, files map[string]RepositoryFile, options *UploadOptions) error {
+	if options == nil {
+		options = &UploadOptions{}
+	}
+	c, err := newArtifactClient()
+	if err != nil {
+		return err
+	}
+	created := struct {
+		OK              bool   `json:"ok"`
+		SignedUploadURL string `json:"signed_upload_url"`
+	}{}
+	err = c.call(ctx, "CreateArtifact", map[string]interface{}{
+		"workflow_run_backend_id":     c.runBackendID,
+		"workflow_job_run_backend_id": c.jobBackendID,
+		"name":                        name,
+		"version":                     artifactVersion,
+	}, &created)
+	if err == nil && !created.OK {
+		err = fmt.Errorf("the artifact service refused the artifact")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create artifact %s: %v", name, err)
+	}
+
+	archive := bytes.NewBuffer(nil)
+	if err := WriteZip(archive, files); err != nil {
+		return fmt.Errorf("failed to archive artifact %s: %v", name, err)
+	}
+	data := archive.Bytes()
+	total := int64(len(data))
+	hash := sha256.Sum256(data)
+
+	blocks := [][2]int64{}
+	for start := int64(0); start < total; start += options.chunkSize() {
+		end := start + options.chunkSize()
+		if end > total {
+			end = total
+		}
+		blocks = append(blocks, [2]int64{start, end})
+	}
+	ids := make([]string, len(blocks))
+	for i := range blocks {
+		// block identifiers must all have the same length
+		ids[i] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%06d", i)))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lock := sync.Mutex{}
+	uploaded := int64(0)
+	progress := func(n int64) {
+		lock.Lock()
+		uploaded += n
+		current := uploaded
+		lock.Unlock()
+		if options.Progress != nil {
+			options.Progress(current, total)
+		}
+	}
+	pending := make(chan int)
+	errs := make(chan error, len(blocks))
+	wg := sync.WaitGroup{}
+	for i := 0; i < options.concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				start, end := blocks[i][0], blocks[i][1]
+				if err := c.uploadBlock(ctx, created.SignedUploadURL, ids[i], data[start:end]); err != nil {
+					errs <- fmt.Errorf("failed to upload artifact %s: %v", name, err)
+					cancel()
+					continue
+				}
+				progress(end - start)
+			}
+		}()
+	}
+	for i := range blocks {
+		select {
+		case pending <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(pending)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := c.commitBlocks(ctx, created.SignedUploadURL, ids); err != nil {
+		return fmt.Errorf("failed to upload artifact %s: %v", name, err)
+	}
+
+	finalized := struct {
+		OK bool `json:"ok"`
+	}{}
+	err = c.call(ctx, "FinalizeArtifact", map[string]interface{}{
+		"workflow_run_backend_id":     c.runBackendID,
+		"workflow_job_run_backend_id": c.jobBackendID,
+		"name":                        name,
+		"size":                        fmt.Sprintf("%d", total),
+		"hash":                        "sha256:" + hex.EncodeToString(hash[:]),
+	}, &finalized)
+	if err == nil && !finalized.OK {
+		err = fmt.Errorf("the artifact service refused the artifact")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to finalize artifact %s: %v", name, err)
+	}
+	return nil
+}
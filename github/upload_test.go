@@ -0,0 +1,195 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runtimeToken returns a runtime token scoped to the artifacts of the run and job backend identifiers
+func runtimeToken(runBackendID, jobBackendID string) string {
+	claims, _ := json.Marshal(map[string]string{"scp": "Actions.GenericRead:00000000 Actions.Results:" + runBackendID + ":" + jobBackendID})
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(claims) + ".c2lnbmF0dXJl"
+}
+
+// artifactService fakes the artifact service and the blob storage of the runner,
+// it only finalizes artifact name once its blocks have been committed
+type artifactService struct {
+	name      string
+	lock      sync.Mutex
+	blocks    map[string][]byte
+	failures  map[string]int
+	blob      []byte
+	finalized int
+	size      string
+	hash      string
+}
+
+func (s *artifactService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if strings.HasPrefix(r.URL.Path, "/"+artifactServicePath) {
+		if r.Header.Get("Authorization") != "Bearer "+runtimeToken("run-backend", "job-backend") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["workflow_run_backend_id"] != "run-backend" || body["workflow_job_run_backend_id"] != "job-backend" || body["name"] != s.name {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code": "invalid_argument", "msg": "unknown artifact"}`)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/"+artifactServicePath) {
+		case "CreateArtifact":
+			s.blocks = map[string][]byte{}
+			s.blob = nil
+			fmt.Fprintf(w, `{"ok": true, "signed_upload_url": "http://%s/blob/artifact.zip?sig=signature"}`, r.Host)
+		case "FinalizeArtifact":
+			if s.blob == nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"code": "failed_precondition", "msg": "the artifact was not uploaded"}`)
+				return
+			}
+			s.size, _ = body["size"].(string)
+			s.hash, _ = body["hash"].(string)
+			s.finalized++
+			fmt.Fprint(w, `{"ok": true, "artifact_id": "1"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+	if r.Method != http.MethodPut || r.URL.Path != "/blob/artifact.zip" || r.URL.Query().Get("sig") != "signature" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.URL.Query().Get("comp") {
+	case "block":
+		id := r.URL.Query().Get("blockid")
+		if s.failures[id] > 0 {
+			s.failures[id]--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		s.blocks[id] = b
+	case "blocklist":
+		list := struct {
+			Latest []string `xml:"Latest"`
+		}{}
+		if err := xml.NewDecoder(r.Body).Decode(&list); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		blob := []byte{}
+		for _, id := range list.Latest {
+			b, ok := s.blocks[id]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			blob = append(blob, b...)
+		}
+		s.blob = blob
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (s *artifactService) files(t *testing.T) map[string]RepositoryFile {
+	files, err := readZip(bytes.NewReader(s.blob), int64(len(s.blob)), MatchesOneOf(".*"), 0, &DownloadOptions{})
+	require.NoError(t, err)
+	return files
+}
+
+func blockID(i int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%06d", i)))
+}
+
+func TestUploadArtifact(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	service := &artifactService{name: "build", failures: map[string]int{blockID(1): 2}}
+	s := httptest.NewServer(service)
+	defer s.Close()
+	env := map[string]string{
+		"ACTIONS_RESULTS_URL":   s.URL + "/",
+		"ACTIONS_RUNTIME_TOKEN": runtimeToken("run-backend", "job-backend"),
+	}
+	defer mockEnv(env)()
+
+	progress := []int64{}
+	lock := sync.Mutex{}
+	total := int64(0)
+	err := UploadArtifact(context.Background(), "build", map[string]RepositoryFile{
+		"a.txt":     {Data: []byte("0123456789")},
+		"b.txt":     {Data: []byte("abc")},
+		"dir/empty": {Data: []byte{}},
+	}, &UploadOptions{Concurrency: 3, ChunkSize: 64, Progress: func(uploaded, size int64) {
+		lock.Lock()
+		defer lock.Unlock()
+		total = size
+		progress = append(progress, uploaded)
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, service.finalized)
+	assert.Equal(t, int64(len(service.blob)), total)
+	assert.Equal(t, fmt.Sprintf("%d", len(service.blob)), service.size)
+	hash := sha256.Sum256(service.blob)
+	assert.Equal(t, "sha256:"+hex.EncodeToString(hash[:]), service.hash)
+	files := service.files(t)
+	assert.Len(t, files, 3)
+	assert.Equal(t, "0123456789", string(files["a.txt"].Data))
+	assert.Equal(t, "abc", string(files["b.txt"].Data))
+	assert.Equal(t, "", string(files["dir/empty"].Data))
+	assert.Len(t, service.blocks, int((total+63)/64), "the archive must be uploaded in blocks of ChunkSize")
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits, "failed blocks must be retried with a backoff")
+	assert.Len(t, progress, len(service.blocks))
+	assert.Equal(t, total, progress[len(progress)-1])
+
+	t.Run("the artifact is not finalized when a block fails to upload", func(t *testing.T) {
+		service.finalized = 0
+		service.failures[blockID(0)] = uploadRetries
+		err := UploadArtifact(context.Background(), "build", map[string]RepositoryFile{
+			"b.txt": {Data: []byte("abc")},
+		}, nil)
+		if assert.Error(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "failed to upload artifact build"))
+		}
+		assert.Equal(t, 0, service.finalized)
+	})
+
+	t.Run("errors of the artifact service are reported", func(t *testing.T) {
+		err := UploadArtifact(context.Background(), "other", nil, nil)
+		if assert.Error(t, err) {
+			assert.True(t, strings.Contains(err.Error(), "unknown artifact"), err.Error())
+		}
+	})
+
+	t.Run("outside of a workflow run the artifact service is not available", func(t *testing.T) {
+		defer mockEnv(map[string]string{"ACTIONS_RESULTS_URL": env["ACTIONS_RESULTS_URL"]})()
+		assert.Error(t, UploadArtifact(context.Background(), "build", nil, nil))
+	})
+
+	t.Run("the runtime token must be scoped to the artifact service", func(t *testing.T) {
+		defer mockEnv(map[string]string{"ACTIONS_RESULTS_URL": env["ACTIONS_RESULTS_URL"], "ACTIONS_RUNTIME_TOKEN": "runtime-token"})()
+		assert.Error(t, UploadArtifact(context.Background(), "build", nil, nil))
+	})
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// githubActionsLogin is the login of the user the workflow GITHUB_TOKEN acts as
+const githubActionsLogin = "github-actions[bot]"
+
+var (
+	loginsLock sync.Mutex
+	// logins caches the authenticated login of each client
+	logins = map[*github.Client]string{}
+)
+
+// AuthenticatedLogin returns the login of the user the GitHub client acts as, for example github-actions[bot].
+// When authenticated as a GitHub App with UseAppAuth, the login of the app bot user is returned.
+// The workflow GITHUB_TOKEN is not allowed to read the authenticated user, github-actions[bot] is returned when the API
+// answers 403 Forbidden, unless the call was rate limited.
+// The login is retrieved once per client and cached for the lifetime of the process
+func AuthenticatedLogin(ctx context.Context) (string, error) {
+	client := GitHub
+	loginsLock.Lock()
+	defer loginsLock.Unlock()
+	if login, ok := logins[client]; ok {
+		return login, nil
+	}
+	if app := currentAppAuth(); app != nil {
+		login, err := app.login(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get the authenticated user: %v", err)
+		}
+		logins[client] = login
+		return login, nil
+	}
+	user, resp, err := client.Users.Get(ctx, "")
+	switch err.(type) {
+	case *github.RateLimitError, *github.AbuseRateLimitError:
+		return "", fmt.Errorf("failed to get the authenticated user: %v", err)
+	}
+	if resp != nil && resp.StatusCode == http.StatusForbidden {
+		// the workflow token can't read the authenticated user, it always acts as the actions bot
+		logins[client] = githubActionsLogin
+		return githubActionsLogin, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get the authenticated user: %v", err)
+	}
+	logins[client] = user.GetLogin()
+	return user.GetLogin(), nil
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticatedLogin(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"login": "my-app[bot]", "type": "Bot"}`)
+	})
+	defer mockGitHub(mux)()
+
+	for i := 0; i < 2; i++ {
+		login, err := AuthenticatedLogin(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "my-app[bot]", login)
+	}
+	assert.Equal(t, 1, calls, "the login must be cached")
+
+	t.Run("the workflow token acts as the actions bot", func(t *testing.T) {
+		calls := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+		})
+		defer mockGitHub(mux)()
+		for i := 0; i < 2; i++ {
+			login, err := AuthenticatedLogin(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "github-actions[bot]", login)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		calls := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "Server Error"}`)
+		})
+		defer mockGitHub(mux)()
+		for i := 0; i < 2; i++ {
+			_, err := AuthenticatedLogin(context.Background())
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("rate limited calls are not cached", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Hour).Unix()))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+		})
+		defer mockGitHub(mux)()
+		_, err := AuthenticatedLogin(context.Background())
+		assert.Error(t, err)
+		loginsLock.Lock()
+		defer loginsLock.Unlock()
+		assert.NotContains(t, logins, GitHub)
+	})
+
+	t.Run("apps act as their bot user", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+		})
+		mux.HandleFunc("/app", func(w http.ResponseWriter, r *http.Request) {
+			assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "), "the app must authenticate with its JWT")
+			fmt.Fprint(w, `{"id": 1234, "slug": "release-bot"}`)
+		})
+		defer mockGitHub(mux)()
+		appAuthAccess.Lock()
+		appAuth = &appTokenSource{appID: 1234, installationID: 42, key: key, baseURL: GitHub.BaseURL}
+		appAuthAccess.Unlock()
+		defer func() {
+			appAuthAccess.Lock()
+			appAuth = nil
+			appAuthAccess.Unlock()
+		}()
+
+		login, err := AuthenticatedLogin(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "release-bot[bot]", login)
+	})
+}
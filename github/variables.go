@@ -0,0 +1,47 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// variableEnv returns the environment variable Variable reads, VARS_ followed by the upper-cased name with dashes replaced by underscores
+func variableEnv(name string) string {
+	return "VARS_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// Variable returns the value of a configuration variable mapped to the job environment.
+// Variables are not exposed to actions unless the workflow maps them, by convention to VARS_<NAME>, for example
+// env: {VARS_DEPLOY_TARGET: ${{ vars.DEPLOY_TARGET }}}. This requires no token permission.
+// ok is false when the variable is not set or empty
+func Variable(name string) (string, bool) {
+	v := getenv(variableEnv(name))
+	return v, v != ""
+}
+
+type actionsVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// RepoVariable reads a configuration variable of the repository running the workflow with the API.
+// The token needs the `actions_variables: read` permission for GitHub Apps or the `repo` scope for personal access tokens,
+// use Variable to avoid requiring it
+func RepoVariable(ctx context.Context, name string) (string, error) {
+	u := fmt.Sprintf("repos/%s/%s/actions/variables/%s", Context.Repo.Owner, Context.Repo.Repo, name)
+	req, err := GitHub.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	v := &actionsVariable{}
+	resp, err := GitHub.Do(ctx, req, v)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", &ErrNotFound{Resource: "variable " + name, Err: err}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get variable %s: %v", name, PermissionError(resp, err))
+	}
+	return v.Value, nil
+}
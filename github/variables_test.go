@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariable(t *testing.T) {
+	defer mockEnv(map[string]string{"VARS_DEPLOY_TARGET": "production"})()
+	v, ok := Variable("deploy-target")
+	assert.True(t, ok)
+	assert.Equal(t, "production", v)
+	_, ok = Variable("missing")
+	assert.False(t, ok)
+}
+
+func TestRepoVariable(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/variables/DEPLOY_TARGET", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "DEPLOY_TARGET", "value": "production"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/variables/MISSING", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	mux.HandleFunc("/repos/actions-go/toolkit/actions/variables/FORBIDDEN", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accepted-GitHub-Permissions", "actions_variables=read")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+	})
+	defer mockGitHub(mux)()
+	defer mockContext(ActionContext{Repo: ActionRepo{Owner: "actions-go", Repo: "toolkit"}})()
+
+	v, err := RepoVariable(context.Background(), "DEPLOY_TARGET")
+	assert.NoError(t, err)
+	assert.Equal(t, "production", v)
+
+	_, err = RepoVariable(context.Background(), "MISSING")
+	assert.IsType(t, &ErrNotFound{}, err)
+
+	_, err = RepoVariable(context.Background(), "FORBIDDEN")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "actions_variables: read")
+	}
+}
@@ -0,0 +1,38 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// CurrentVersion returns the semantic version of the tag that triggered the workflow, with or without a leading v.
+// An error is returned when the workflow was not triggered by a tag or when the tag is not a semantic version
+func CurrentVersion() (*semver.Version, error) {
+	ref := ParseRef()
+	if ref.Kind != RefKindTag {
+		return nil, fmt.Errorf("ref %s is not a tag", Context.Ref)
+	}
+	v, err := semver.StrictNewVersion(strings.TrimPrefix(ref.Name, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("tag %s is not a semantic version: %v", ref.Name, err)
+	}
+	return v, nil
+}
+
+// SemverFromRef returns the components of the semantic version of the tag that triggered the workflow, see CurrentVersion
+func SemverFromRef() (major, minor, patch int, prerelease, build string, err error) {
+	v, err := CurrentVersion()
+	if err != nil {
+		return 0, 0, 0, "", "", err
+	}
+	return int(v.Major()), int(v.Minor()), int(v.Patch()), v.Prerelease(), v.Metadata(), nil
+}
+
+// IsPreRelease returns whether the workflow was triggered by a pre-release tag, for example v1.2.3-rc.1.
+// False is returned when the ref is not a semantic version tag
+func IsPreRelease() bool {
+	v, err := CurrentVersion()
+	return err == nil && v.Prerelease() != ""
+}
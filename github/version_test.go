@@ -0,0 +1,49 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRef(t *testing.T) {
+	assert.Equal(t, Ref{Kind: RefKindBranch, Name: "feature/a"}, parseRef("refs/heads/feature/a"))
+	assert.Equal(t, Ref{Kind: RefKindTag, Name: "v1.2.3"}, parseRef("refs/tags/v1.2.3"))
+	assert.Equal(t, Ref{Kind: RefKindPullRequest, Name: "12/merge"}, parseRef("refs/pull/12/merge"))
+	assert.Equal(t, Ref{Name: "main"}, parseRef("main"))
+}
+
+func TestSemverFromRef(t *testing.T) {
+	defer mockContext(ActionContext{Ref: "refs/tags/v1.2.3"})()
+	major, minor, patch, prerelease, build, err := SemverFromRef()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, []int{major, minor, patch})
+	assert.Equal(t, "", prerelease)
+	assert.Equal(t, "", build)
+	assert.False(t, IsPreRelease())
+
+	Context.Ref = "refs/tags/1.2.3-rc.1+build"
+	major, minor, patch, prerelease, build, err = SemverFromRef()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, []int{major, minor, patch})
+	assert.Equal(t, "rc.1", prerelease)
+	assert.Equal(t, "build", build)
+	assert.True(t, IsPreRelease())
+
+	v, err := CurrentVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3-rc.1+build", v.String())
+
+	Context.Ref = "refs/heads/v1.2.3"
+	_, _, _, _, _, err = SemverFromRef()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not a tag")
+	}
+	assert.False(t, IsPreRelease())
+
+	Context.Ref = "refs/tags/latest"
+	_, err = CurrentVersion()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not a semantic version")
+	}
+}
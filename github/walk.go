@@ -0,0 +1,39 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WalkMatch returns the sorted path of files under root that the include matcher accepts, skipping .git directories.
+// Paths are slash separated and relative to root, like the paths of downloaded files, so that the same matchers
+// select the same files whether they are processed from an archive or from the workspace, see Workspace.
+// Symbolic links are listed but not followed
+func WalkMatch(root string, include Matcher) ([]string, error) {
+	paths := []string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if include(rel) {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %v", root, err)
+	}
+	return paths, nil
+}
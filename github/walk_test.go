@@ -0,0 +1,33 @@
+package github
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "walk-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, p := range []string{"main.go", "README.md", "core/core.go", "core/testdata/input.txt", ".git/HEAD", ".git/hooks/pre-commit.go"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, p), []byte(p), 0644))
+	}
+	require.NoError(t, os.Symlink("core.go", filepath.Join(dir, "core", "link.go")))
+
+	files, err := WalkMatch(dir, MatchesOneOf("\\.go$"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"core/core.go", "core/link.go", "main.go"}, files)
+
+	files, err = WalkMatch(dir, GitignoreMatcher("testdata/\n*.md\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "core/testdata/input.txt"}, files)
+
+	_, err = WalkMatch(filepath.Join(dir, "does-not-exist"), MatchesOneOf(".*"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+const (
+	webhookTimeout       = 30 * time.Second
+	webhookRetries       = 3
+	webhookRetryInterval = time.Second
+)
+
+// redactURL hides the path and query of a URL, webhook URLs usually embed their secret
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Host == "" {
+		return "***"
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/***"
+}
+
+// PostWebhook sends payload, encoded as JSON, to an external URL, for example a Slack or Teams incoming webhook.
+// Requests failing with a 5xx status are retried with an exponential backoff. When ctx has no deadline, the whole
+// operation times out after 30 seconds. The URL is never logged as it usually contains a secret.
+// The response is only returned on success, its body is read beforehand so that it outlives the timeout.
+// Otherwise the error holds the status and the body returned by the webhook
+func PostWebhook(ctx context.Context, target string, payload interface{}, headers map[string]string) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %v", err)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, webhookTimeout)
+		defer cancel()
+	}
+	interval := webhookRetryInterval
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create webhook request to %s: %v", redactURL(target), err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := baseHTTPClient().Do(req.WithContext(ctx))
+		if err != nil {
+			// the client error embeds the URL, only keep its cause
+			if e, ok := err.(*url.Error); ok {
+				err = e.Err
+			}
+			return nil, fmt.Errorf("failed to post webhook to %s: %v", redactURL(target), err)
+		}
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook %s response: %v", redactURL(target), err)
+		}
+		if resp.StatusCode < 300 {
+			resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+			return resp, nil
+		}
+		if resp.StatusCode < 500 {
+			return nil, fmt.Errorf("webhook %s answered with code %d: %s", redactURL(target), resp.StatusCode, strings.TrimSpace(string(b)))
+		}
+		if attempt == webhookRetries {
+			return nil, fmt.Errorf("webhook %s answered with code %d after %d attempts: %s", redactURL(target), resp.StatusCode, attempt, strings.TrimSpace(string(b)))
+		}
+		core.Debugf("webhook %s answered with code %d, retrying in %v", redactURL(target), resp.StatusCode, interval)
+		if err := sleep(ctx, interval); err != nil {
+			return nil, fmt.Errorf("failed to post webhook to %s: %v", redactURL(target), err)
+		}
+		interval *= 2
+	}
+}
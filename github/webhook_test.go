@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostWebhook(t *testing.T) {
+	waits := []time.Duration{}
+	defer mockClock(&waits)()
+	calls := 0
+	failures := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Query().Get("channel") == "unknown" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("channel_not_found"))
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "value", r.Header.Get("X-Custom"))
+		payload := map[string]string{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "build succeeded", payload["text"])
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+	secretURL := s.URL + "/services/T000/B000/secret"
+	post := func(ctx context.Context) (*http.Response, error) {
+		return PostWebhook(ctx, secretURL, map[string]string{"text": "build succeeded"}, map[string]string{"X-Custom": "value"})
+	}
+
+	resp, err := post(context.Background())
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.NoError(t, err, "the body must be readable once the timeout is released")
+	assert.Equal(t, "ok", string(b))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+
+	t.Run("5xx responses are retried", func(t *testing.T) {
+		calls, failures, waits = 0, 2, waits[:0]
+		resp, err := post(context.Background())
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, waits)
+
+		calls, failures = 0, webhookRetries
+		resp, err = post(context.Background())
+		if assert.Error(t, err) {
+			assert.NotContains(t, err.Error(), "secret")
+		}
+		assert.Nil(t, resp)
+		assert.Equal(t, webhookRetries, calls)
+	})
+
+	t.Run("4xx responses are reported along with their body", func(t *testing.T) {
+		calls, failures = 0, 0
+		resp, err := PostWebhook(context.Background(), secretURL+"?channel=unknown", nil, nil)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "code 404: channel_not_found")
+			assert.NotContains(t, err.Error(), "secret")
+		}
+		assert.Nil(t, resp)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("requests time out", func(t *testing.T) {
+		blocked := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-blocked
+		}))
+		defer slow.Close()
+		defer close(blocked)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := PostWebhook(ctx, slow.URL+"/secret", nil, nil)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "deadline exceeded")
+			assert.NotContains(t, err.Error(), "secret")
+		}
+	})
+}
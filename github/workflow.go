@@ -0,0 +1,79 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actions-go/toolkit/core"
+)
+
+// WorkflowRef identifies a workflow file at a given ref, as found in GITHUB_WORKFLOW_REF
+type WorkflowRef struct {
+	// Repository holding the workflow, for example actions-go/toolkit
+	Repository string
+	// Path of the workflow file in the repository, for example .github/workflows/ci.yml
+	Path string
+	// Ref the workflow has been read from, for example refs/heads/main
+	Ref string
+}
+
+func (r WorkflowRef) String() string {
+	return r.Repository + "/" + r.Path + "@" + r.Ref
+}
+
+// ParseWorkflowRef parses a workflow reference formatted as owner/repo/path/to/workflow.yml@ref
+func ParseWorkflowRef(ref string) (WorkflowRef, error) {
+	at := strings.LastIndex(ref, "@")
+	if at < 0 {
+		return WorkflowRef{}, fmt.Errorf("invalid workflow ref %s: missing @ref", ref)
+	}
+	parts := strings.SplitN(ref[:at], "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return WorkflowRef{}, fmt.Errorf("invalid workflow ref %s: expected owner/repo/path@ref", ref)
+	}
+	return WorkflowRef{Repository: parts[0] + "/" + parts[1], Path: parts[2], Ref: ref[at+1:]}, nil
+}
+
+// CallerWorkflowRef returns the top-level workflow of the run, read from GITHUB_WORKFLOW_REF.
+// In a reusable workflow, GITHUB_WORKFLOW_REF refers to the caller workflow rather than the called one,
+// this makes it the workflow to report for provenance. ok is false when the variable is missing or invalid
+func CallerWorkflowRef() (WorkflowRef, bool) {
+	ref, err := ParseWorkflowRef(githubEnv("WORKFLOW_REF"))
+	return ref, err == nil
+}
+
+// IsReusableWorkflow returns whether the current job is likely running in a reusable workflow, called with workflow_call.
+// GitHub does not expose this information explicitly, the heuristic relies on GITHUB_WORKFLOW holding the path of the
+// workflow file when the workflow has no name: when this path differs from the one of GITHUB_WORKFLOW_REF, which always
+// refers to the caller, the job runs in a called workflow.
+// As a consequence, false is returned for reusable workflows declaring a name, or when either variable is missing
+func IsReusableWorkflow() bool {
+	caller, ok := CallerWorkflowRef()
+	if !ok {
+		return false
+	}
+	workflow := githubEnv("WORKFLOW")
+	if !strings.HasPrefix(workflow, ".github/workflows/") {
+		return false
+	}
+	return workflow != caller.Path
+}
+
+// CallInput returns the value of an input passed to a reusable workflow, or to a manually dispatched one, as found in the
+// event payload. Boolean and number inputs are formatted with fmt.Sprint.
+// When the payload does not hold the input, for example in an action step, the action input is returned as per core.GetInput
+func CallInput(name string) (string, bool) {
+	if v, ok := Context.Payload.Inputs[name]; ok && v != nil {
+		return fmt.Sprint(v), true
+	}
+	return core.GetInput(name)
+}
+
+// HasCallSecret returns whether a secret appears to be available to the job, on a best-effort basis.
+// Secret values are never exposed to the payload and a reusable workflow can't list the secrets it received:
+// this only reports whether an environment variable named after the secret, upper-cased with dashes replaced by
+// underscores, is set and not empty. It is only reliable when the workflow maps the secret to such a variable,
+// for example env: {DEPLOY_TOKEN: ${{ secrets.deploy-token }}}
+func HasCallSecret(name string) bool {
+	return getenv(strings.ToUpper(strings.Replace(name, "-", "_", -1))) != ""
+}
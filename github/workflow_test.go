@@ -0,0 +1,80 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWorkflowRef(t *testing.T) {
+	ref, err := ParseWorkflowRef("actions-go/toolkit/.github/workflows/ci.yml@refs/heads/main")
+	assert.NoError(t, err)
+	assert.Equal(t, WorkflowRef{Repository: "actions-go/toolkit", Path: ".github/workflows/ci.yml", Ref: "refs/heads/main"}, ref)
+	assert.Equal(t, "actions-go/toolkit/.github/workflows/ci.yml@refs/heads/main", ref.String())
+
+	_, err = ParseWorkflowRef("actions-go/toolkit/.github/workflows/ci.yml")
+	assert.Error(t, err)
+	_, err = ParseWorkflowRef("toolkit@refs/heads/main")
+	assert.Error(t, err)
+}
+
+func TestIsReusableWorkflow(t *testing.T) {
+	t.Run("in a directly triggered workflow", func(t *testing.T) {
+		env := map[string]string{
+			"GITHUB_WORKFLOW":     "CI",
+			"GITHUB_WORKFLOW_REF": "actions-go/toolkit/.github/workflows/ci.yml@refs/heads/main",
+		}
+		defer mockEnv(env)()
+		assert.False(t, IsReusableWorkflow())
+		caller, ok := CallerWorkflowRef()
+		assert.True(t, ok)
+		assert.Equal(t, ".github/workflows/ci.yml", caller.Path)
+
+		env["GITHUB_WORKFLOW"] = ".github/workflows/ci.yml"
+		assert.False(t, IsReusableWorkflow(), "unnamed workflows are reported by path")
+	})
+	t.Run("in a reusable workflow", func(t *testing.T) {
+		defer mockEnv(map[string]string{
+			"GITHUB_WORKFLOW":     ".github/workflows/build.yml",
+			"GITHUB_WORKFLOW_REF": "actions-go/toolkit/.github/workflows/ci.yml@refs/heads/main",
+		})()
+		assert.True(t, IsReusableWorkflow())
+		caller, ok := CallerWorkflowRef()
+		assert.True(t, ok)
+		assert.Equal(t, "actions-go/toolkit", caller.Repository)
+		assert.Equal(t, ".github/workflows/ci.yml", caller.Path)
+	})
+	t.Run("without workflow ref", func(t *testing.T) {
+		defer mockEnv(map[string]string{"GITHUB_WORKFLOW": ".github/workflows/build.yml"})()
+		assert.False(t, IsReusableWorkflow())
+		_, ok := CallerWorkflowRef()
+		assert.False(t, ok)
+	})
+}
+
+func TestCallInput(t *testing.T) {
+	defer setEnv(map[string]string{"GITHUB_EVENT_PATH": "workflow_call_event.json", "INPUT_TOKEN": "from-action-input"})()
+	defer mockContext(ParseActionEnv())()
+
+	v, ok := CallInput("environment")
+	assert.True(t, ok)
+	assert.Equal(t, "staging", v)
+	v, _ = CallInput("dry-run")
+	assert.Equal(t, "true", v)
+	v, _ = CallInput("replicas")
+	assert.Equal(t, "3", v)
+
+	v, ok = CallInput("token")
+	assert.True(t, ok)
+	assert.Equal(t, "from-action-input", v, "inputs missing from the payload fall back to action inputs")
+	_, ok = CallInput("missing")
+	assert.False(t, ok)
+}
+
+func TestHasCallSecret(t *testing.T) {
+	defer mockEnv(map[string]string{"DEPLOY_TOKEN": "secret", "EMPTY_SECRET": ""})()
+	assert.True(t, HasCallSecret("deploy-token"))
+	assert.True(t, HasCallSecret("DEPLOY_TOKEN"))
+	assert.False(t, HasCallSecret("empty-secret"))
+	assert.False(t, HasCallSecret("missing"))
+}
@@ -8,4 +8,5 @@ require (
 	github.com/google/uuid v1.2.0
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	gopkg.in/yaml.v3 v3.0.1
 )